@@ -0,0 +1,173 @@
+package twitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nicklaw5/helix/v2"
+)
+
+// appTokenRetry is how long to wait before retrying a failed app access token request.
+const appTokenRetry = time.Minute
+
+// appTokens acquires the app access token with the client credentials grant, renews it
+// before it expires and shares it between the bot's clients.
+type appTokens struct {
+	bot      *Bot
+	clients  []*helix.Client
+	renewing sync.Mutex // held while requesting a token, so concurrent 401s request one
+	mu       sync.Mutex
+	token    string
+	previous string
+	renewAt  time.Time
+}
+
+func newAppTokens(b *Bot, clients ...*helix.Client) *appTokens {
+	return &appTokens{bot: b, clients: clients}
+}
+
+// current returns the app access token, or an empty string if none has been acquired.
+func (a *appTokens) current() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.token
+}
+
+// issued reports whether token is the current or previous app access token.
+func (a *appTokens) issued(token string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return token != "" && (token == a.token || token == a.previous)
+}
+
+// acquire requests a new app access token and hands it to every client.
+func (a *appTokens) acquire(ctx context.Context) (string, error) {
+	a.renewing.Lock()
+	defer a.renewing.Unlock()
+	return a.request(ctx)
+}
+
+// renew replaces a rejected app access token, unless it has already been replaced.
+func (a *appTokens) renew(ctx context.Context, rejected string) (string, error) {
+	a.renewing.Lock()
+	defer a.renewing.Unlock()
+
+	if token := a.current(); token != rejected {
+		return token, nil
+	}
+	return a.request(ctx)
+}
+
+func (a *appTokens) request(ctx context.Context) (string, error) {
+	data := url.Values{}
+	data.Set("client_id", a.bot.config.ClientID)
+	data.Set("client_secret", a.bot.secret("CLIENT_SECRET"))
+	data.Set("grant_type", "client_credentials")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://id.twitch.tv/oauth2/token", strings.NewReader(data.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.bot.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed requesting app access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed requesting app access token: unexpected status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed decoding app access token: %w", err)
+	}
+
+	// without a lifetime the token would be renewed immediately, and again, in a tight loop
+	if body.AccessToken == "" || body.ExpiresIn <= 0 {
+		return "", fmt.Errorf("failed requesting app access token: response has no token or lifetime (expires_in %d)", body.ExpiresIn)
+	}
+
+	// renew once nine tenths of the lifetime has passed, well before expires_in
+	lifetime := time.Duration(body.ExpiresIn) * time.Second
+	now := a.bot.now()
+
+	a.mu.Lock()
+	a.previous, a.token = a.token, body.AccessToken
+	a.renewAt = now.Add(lifetime * 9 / 10)
+	a.mu.Unlock()
+
+	for _, client := range a.clients {
+		client.SetAppAccessToken(body.AccessToken)
+	}
+
+	a.bot.logger.Info().
+		Dur("expires_in", lifetime).
+		Msg("acquired app access token")
+	return body.AccessToken, nil
+}
+
+// run renews the app access token before it expires until ctx is done, retrying failed requests.
+func (a *appTokens) run(ctx context.Context) {
+	for {
+		a.mu.Lock()
+		wait := a.renewAt.Sub(a.bot.now())
+		a.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if _, err := a.acquire(ctx); err != nil && ctx.Err() == nil {
+			a.bot.logger.Error().Err(err).Msg("failed to renew app access token")
+			a.retryLater()
+		}
+	}
+}
+
+// retryLater schedules the next request after appTokenRetry.
+func (a *appTokens) retryLater() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.renewAt = a.bot.now().Add(appTokenRetry)
+}
+
+// start acquires the app access token and keeps renewing it until ctx is done.
+//
+// A failed first request is retried in the background, so a bot without a client secret
+// may still run on user tokens alone.
+func (a *appTokens) start(ctx context.Context) {
+	if _, err := a.acquire(ctx); err != nil {
+		a.bot.logger.Warn().Err(err).Msg("failed to acquire app access token, retrying")
+		a.retryLater()
+	}
+	go a.run(ctx)
+}
+
+// AppHelix returns the Helix client authorized with the app access token, acquired when
+// the bot starts and renewed before it expires.
+//
+// Unlike the client returned by Helix, it never uses a user token, as required by
+// webhook subscriptions and conduits.
+//
+// Example:
+//
+//	resp, err := bot.AppHelix().GetEventSubSubscriptions(&helix.EventSubSubscriptionsParams{})
+func (b *Bot) AppHelix() *helix.Client {
+	return b.app
+}
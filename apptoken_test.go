@@ -0,0 +1,116 @@
+package twitchgo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testClock is a Clock that only moves when advanced.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// tokenEndpoint answers client credentials requests with the bodies in turn, repeating
+// the last, and sends each request's form to requests.
+func tokenEndpoint(requests chan<- url.Values, bodies ...string) roundTripFunc {
+	var mu sync.Mutex
+	return func(r *http.Request) (*http.Response, error) {
+		data, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(data))
+		requests <- form
+
+		mu.Lock()
+		body := bodies[0]
+		if len(bodies) > 1 {
+			bodies = bodies[1:]
+		}
+		mu.Unlock()
+		return jsonResponse(r, http.StatusOK, body), nil
+	}
+}
+
+func TestAppTokenAcquireAndRenew(t *testing.T) {
+	clock := &testClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	requests := make(chan url.Values, 4)
+	b := newTestBot(t, nil, WithClock(clock), WithTransport(tokenEndpoint(requests,
+		`{"access_token":"app-1","expires_in":1000}`,
+		`{"access_token":"app-2","expires_in":1000}`,
+	)))
+
+	token, err := b.appTokens.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if token != "app-1" || b.app.GetAppAccessToken() != "app-1" || b.helix.GetAppAccessToken() != "app-1" {
+		t.Errorf("acquired %q, app client %q, user client %q, want app-1 handed to both", token, b.app.GetAppAccessToken(), b.helix.GetAppAccessToken())
+	}
+
+	form := receive(t, requests, "token request")
+	if form.Get("grant_type") != "client_credentials" || form.Get("client_id") != b.config.ClientID || form.Get("client_secret") != b.secret("CLIENT_SECRET") {
+		t.Errorf("token request = %v, want the client credentials grant", form)
+	}
+
+	b.appTokens.mu.Lock()
+	renewAt := b.appTokens.renewAt
+	b.appTokens.mu.Unlock()
+	if want := clock.Now().Add(900 * time.Second); !renewAt.Equal(want) {
+		t.Errorf("renewal scheduled at %v, want %v, nine tenths into the lifetime", renewAt, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clock.advance(900 * time.Second)
+	go b.appTokens.run(ctx)
+
+	receive(t, requests, "renewal once due")
+	if !b.appTokens.issued("app-1") || b.appTokens.current() != "app-2" {
+		t.Errorf("current token %q, want app-2 with app-1 still recognised", b.appTokens.current())
+	}
+
+	// the renewed token is not due for another 900 seconds
+	select {
+	case <-requests:
+		t.Error("renewed again before the new token was due")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestAppTokenWithoutLifetimeIsRejected(t *testing.T) {
+	clock := &testClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	requests := make(chan url.Values, 4)
+	b := newTestBot(t, nil, WithClock(clock), WithTransport(tokenEndpoint(requests, `{"access_token":"app-1"}`)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b.appTokens.start(ctx)
+
+	receive(t, requests, "token request")
+	if token := b.appTokens.current(); token != "" {
+		t.Errorf("current token %q, want a token without expires_in rejected", token)
+	}
+
+	// the failed request is retried after appTokenRetry, not immediately
+	select {
+	case <-requests:
+		t.Error("token requested again immediately")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -11,11 +11,13 @@ type dedupeCache struct {
 	ttl       time.Duration
 	entries   map[string]time.Time
 	evictList *list.List
+	now       func() time.Time
 }
 
-func newDedupeCache(ttl time.Duration) *dedupeCache {
+func newDedupeCache(ttl time.Duration, now func() time.Time) *dedupeCache {
 	return &dedupeCache{
 		ttl:       ttl,
+		now:       now,
 		entries:   make(map[string]time.Time),
 		evictList: list.New(),
 	}
@@ -29,7 +31,7 @@ func (c *dedupeCache) Exists(id string) bool {
 	if !ok {
 		return false
 	}
-	if time.Since(t) > c.ttl {
+	if c.now().Sub(t) > c.ttl {
 		delete(c.entries, id)
 		return false
 	}
@@ -40,7 +42,7 @@ func (c *dedupeCache) Add(id string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	now := time.Now()
+	now := c.now()
 	c.entries[id] = now
 	c.evictList.PushBack(id)
 
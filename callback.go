@@ -6,9 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"os"
-
-	"github.com/Etwodev/twitchgo/pkg/config"
+	"time"
 )
 
 func (b *Bot) HandleCallback(w http.ResponseWriter, r *http.Request) {
@@ -31,13 +29,13 @@ func (b *Bot) HandleCallback(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := url.Values{}
-	data.Set("client_id", config.ClientID())
-	data.Set("client_secret", os.Getenv("CLIENT_SECRET"))
+	data.Set("client_id", b.config.ClientID)
+	data.Set("client_secret", b.secret("CLIENT_SECRET"))
 	data.Set("code", code)
 	data.Set("grant_type", "authorization_code")
-	data.Set("redirect_uri", config.RedirectUri())
+	data.Set("redirect_uri", b.config.RedirectUri)
 
-	resp, err := http.PostForm("https://id.twitch.tv/oauth2/token", data)
+	resp, err := b.client.PostForm("https://id.twitch.tv/oauth2/token", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -66,10 +64,39 @@ func (b *Bot) HandleCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	b.helix.SetUserAccessToken(body.AccessToken)
-	b.helix.SetRefreshToken(body.RefreshToken)
+	info, err := b.validateToken(r.Context(), body.AccessToken)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("failed to validate access token")
+		http.Error(w, "failed to identify user", http.StatusInternalServerError)
+		return
+	}
+
+	obtained := b.now()
+	token := Token{
+		UserID:       info.UserID,
+		Login:        info.Login,
+		AccessToken:  body.AccessToken,
+		RefreshToken: body.RefreshToken,
+		Scopes:       info.Scopes,
+		ExpiresAt:    obtained.Add(time.Duration(info.ExpiresIn) * time.Second),
+		ObtainedAt:   obtained,
+	}
+	client, err := b.login(token)
+	if err != nil {
+		b.logger.Error().Err(err).Str("user_id", token.UserID).Msg("failed to log in user")
+		http.Error(w, "failed to log in user", http.StatusInternalServerError)
+		return
+	}
+
+	// record whether the user is the bot's own account, so a restart resumes the right one
+	token.Bot = client == b.helix
+	if err := b.tokens.Save(r.Context(), token); err != nil {
+		b.logger.Error().Err(err).Str("user_id", token.UserID).Msg("failed to save token")
+	}
+
+	b.engine.OnClientLogin(r.Context(), client)
+	go b.resubscribe(b.ctx, authorizedBy(token.UserID))
 
-	b.engine.OnClientLogin(r.Context(), b.helix)
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte(fmt.Sprintf("Access token stored successfully. Expires in %d seconds.", body.ExpiresIn)))
 }
@@ -0,0 +1,371 @@
+package twitchgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/nicklaw5/helix/v2"
+)
+
+// EventSubConduit is an EventSub conduit, which fans subscriptions out across one or more shards.
+//
+// See: https://dev.twitch.tv/docs/eventsub/handling-conduit-events for more information.
+type EventSubConduit struct {
+	ID         string `json:"id"`
+	ShardCount int    `json:"shard_count"`
+}
+
+// ConduitShard is a single shard of a conduit and the transport it delivers to.
+type ConduitShard struct {
+	ID        string           `json:"id"`
+	Status    string           `json:"status"`
+	Transport ConduitTransport `json:"transport"`
+}
+
+// ConduitTransport is the transport a conduit shard delivers to.
+type ConduitTransport struct {
+	Method         Method     `json:"method"`
+	Callback       string     `json:"callback,omitempty"`
+	Secret         string     `json:"secret,omitempty"`
+	SessionID      string     `json:"session_id,omitempty"`
+	ConnectedAt    *time.Time `json:"connected_at,omitempty"`
+	DisconnectedAt *time.Time `json:"disconnected_at,omitempty"`
+}
+
+// ConduitShardError reports a shard that could not be updated.
+type ConduitShardError struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+	Code    string `json:"code"`
+}
+
+// ConduitManager manages EventSub conduits and assigns their shards to our transports.
+//
+// Conduit endpoints require an app access token, which is taken from the helix client.
+type ConduitManager struct {
+	bot     *Bot
+	api     *helix.Client
+	baseURL string
+	client  *http.Client
+	mu      sync.Mutex
+	binds   map[string]string
+}
+
+// NewConduitManager creates a ConduitManager that calls the Helix API at baseURL.
+//
+// If baseURL is empty, helix.DefaultAPIBaseURL is used.
+//
+// Example:
+//
+//	cm := twitchgo.NewConduitManager(bot, bot.Helix(), "")
+func NewConduitManager(b *Bot, api *helix.Client, baseURL string) *ConduitManager {
+	if baseURL == "" {
+		baseURL = helix.DefaultAPIBaseURL
+	}
+	return &ConduitManager{
+		bot:     b,
+		api:     api,
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		binds:   make(map[string]string),
+	}
+}
+
+// Conduits lists the conduits owned by the client ID.
+func (m *ConduitManager) Conduits(ctx context.Context) ([]EventSubConduit, error) {
+	var out struct {
+		Data []EventSubConduit `json:"data"`
+	}
+	if err := m.do(ctx, http.MethodGet, "/eventsub/conduits", nil, nil, &out); err != nil {
+		return nil, fmt.Errorf("Conduits: %w", err)
+	}
+	return out.Data, nil
+}
+
+// CreateConduit creates a conduit with shardCount shards.
+func (m *ConduitManager) CreateConduit(ctx context.Context, shardCount int) (EventSubConduit, error) {
+	var out struct {
+		Data []EventSubConduit `json:"data"`
+	}
+	body := map[string]int{"shard_count": shardCount}
+	if err := m.do(ctx, http.MethodPost, "/eventsub/conduits", nil, body, &out); err != nil {
+		return EventSubConduit{}, fmt.Errorf("CreateConduit: %w", err)
+	}
+	if len(out.Data) == 0 {
+		return EventSubConduit{}, fmt.Errorf("CreateConduit: empty response")
+	}
+	return out.Data[0], nil
+}
+
+// UpdateConduit changes the number of shards of a conduit.
+func (m *ConduitManager) UpdateConduit(ctx context.Context, id string, shardCount int) (EventSubConduit, error) {
+	var out struct {
+		Data []EventSubConduit `json:"data"`
+	}
+	body := EventSubConduit{ID: id, ShardCount: shardCount}
+	if err := m.do(ctx, http.MethodPatch, "/eventsub/conduits", nil, body, &out); err != nil {
+		return EventSubConduit{}, fmt.Errorf("UpdateConduit: %w", err)
+	}
+	if len(out.Data) == 0 {
+		return EventSubConduit{}, fmt.Errorf("UpdateConduit: empty response")
+	}
+	return out.Data[0], nil
+}
+
+// DeleteConduit deletes a conduit and all of its subscriptions.
+func (m *ConduitManager) DeleteConduit(ctx context.Context, id string) error {
+	if err := m.do(ctx, http.MethodDelete, "/eventsub/conduits", url.Values{"id": {id}}, nil, nil); err != nil {
+		return fmt.Errorf("DeleteConduit: %w", err)
+	}
+	return nil
+}
+
+// Shards lists the shards of a conduit, following pagination.
+func (m *ConduitManager) Shards(ctx context.Context, conduitID string) ([]ConduitShard, error) {
+	var shards []ConduitShard
+	query := url.Values{"conduit_id": {conduitID}}
+
+	for {
+		var out struct {
+			Data       []ConduitShard   `json:"data"`
+			Pagination helix.Pagination `json:"pagination"`
+		}
+		if err := m.do(ctx, http.MethodGet, "/eventsub/conduits/shards", query, nil, &out); err != nil {
+			return nil, fmt.Errorf("Shards: %w", err)
+		}
+
+		shards = append(shards, out.Data...)
+
+		if out.Pagination.Cursor == "" {
+			return shards, nil
+		}
+		query.Set("after", out.Pagination.Cursor)
+	}
+}
+
+// UpdateShards assigns transports to shards of a conduit.
+//
+// Shards that could not be updated are returned alongside a nil error.
+func (m *ConduitManager) UpdateShards(ctx context.Context, conduitID string, shards []ConduitShard) ([]ConduitShardError, error) {
+	type shardUpdate struct {
+		ID        string           `json:"id"`
+		Transport ConduitTransport `json:"transport"`
+	}
+
+	body := struct {
+		ConduitID string        `json:"conduit_id"`
+		Shards    []shardUpdate `json:"shards"`
+	}{ConduitID: conduitID}
+
+	for _, s := range shards {
+		t := s.Transport
+		t.ConnectedAt, t.DisconnectedAt = nil, nil
+		body.Shards = append(body.Shards, shardUpdate{ID: s.ID, Transport: t})
+	}
+
+	var out struct {
+		Errors []ConduitShardError `json:"errors"`
+	}
+	if err := m.do(ctx, http.MethodPatch, "/eventsub/conduits/shards", nil, body, &out); err != nil {
+		return nil, fmt.Errorf("UpdateShards: %w", err)
+	}
+	return out.Errors, nil
+}
+
+// AssignWebhookShard points a shard of a conduit at a webhook callback.
+//
+// Notifications are signed with CLIENT_SECRET, as the webhook handler expects.
+func (m *ConduitManager) AssignWebhookShard(ctx context.Context, conduitID, shardID, callback string) error {
+	return m.assign(ctx, conduitID, ConduitShard{
+		ID: shardID,
+		Transport: ConduitTransport{
+			Method:   Webhook,
+			Callback: callback,
+			Secret:   m.bot.secret("CLIENT_SECRET"),
+		},
+	})
+}
+
+// AssignWebsocketShard points a shard of a conduit at a websocket session.
+func (m *ConduitManager) AssignWebsocketShard(ctx context.Context, conduitID, shardID, sessionID string) error {
+	return m.assign(ctx, conduitID, ConduitShard{
+		ID: shardID,
+		Transport: ConduitTransport{
+			Method:    Websocket,
+			SessionID: sessionID,
+		},
+	})
+}
+
+func (m *ConduitManager) assign(ctx context.Context, conduitID string, shard ConduitShard) error {
+	errs, err := m.UpdateShards(ctx, conduitID, []ConduitShard{shard})
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("assign: failed assigning shard %s: %s %s", errs[0].ID, errs[0].Code, errs[0].Message)
+	}
+
+	m.bot.logger.Info().
+		Str("conduit_id", conduitID).
+		Str("shard_id", shard.ID).
+		Str("method", string(shard.Transport.Method)).
+		Msg("assigned conduit shard")
+	return nil
+}
+
+// BindWebsocketShard keeps a shard of a conduit assigned to the bot's websocket session,
+// reassigning it whenever a new session is established or the shard is disabled.
+//
+// Example:
+//
+//	bot.Conduits().BindWebsocketShard(conduitID, "0")
+func (m *ConduitManager) BindWebsocketShard(conduitID, shardID string) {
+	m.mu.Lock()
+	m.binds[conduitID] = shardID
+	m.mu.Unlock()
+
+	m.bot.websocket.OnSession(func(ctx context.Context, session WebsocketSession) {
+		if err := m.AssignWebsocketShard(ctx, conduitID, shardID, session.ID); err != nil {
+			m.bot.logger.Error().Err(err).Str("conduit_id", conduitID).Msg("failed to reassign conduit shard")
+		}
+	})
+}
+
+// handleShardDisabled reassigns a bound shard to the current websocket session
+// when Twitch reports it as disabled.
+func (m *ConduitManager) handleShardDisabled(ctx context.Context, event EventSubConduitShardDisabledEvent) {
+	m.bot.logger.Warn().
+		Str("conduit_id", event.ConduitID).
+		Str("shard_id", event.ShardID).
+		Str("status", event.Status).
+		Msg("conduit shard disabled")
+
+	m.mu.Lock()
+	shardID, ok := m.binds[event.ConduitID]
+	m.mu.Unlock()
+
+	if !ok || shardID != event.ShardID {
+		return
+	}
+
+	session := m.bot.websocket.Session()
+	if session.ID == "" {
+		return
+	}
+
+	if err := m.AssignWebsocketShard(ctx, event.ConduitID, shardID, session.ID); err != nil {
+		m.bot.logger.Error().Err(err).Str("conduit_id", event.ConduitID).Msg("failed to reassign conduit shard")
+	}
+}
+
+// CreateSubscription creates an EventSub subscription delivered through a conduit.
+func (m *ConduitManager) CreateSubscription(ctx context.Context, conduitID, typ, version string, condition helix.EventSubCondition) error {
+	body := struct {
+		Type      string                  `json:"type"`
+		Version   string                  `json:"version"`
+		Condition helix.EventSubCondition `json:"condition"`
+		Transport Transport               `json:"transport"`
+	}{
+		Type:      typ,
+		Version:   version,
+		Condition: condition,
+		Transport: Transport{Method: Conduit, ConduitID: conduitID},
+	}
+
+	if err := m.do(ctx, http.MethodPost, "/eventsub/subscriptions", nil, body, nil); err != nil {
+		return fmt.Errorf("CreateSubscription: %w", err)
+	}
+	return nil
+}
+
+// subscriptionConduits returns the conduit each conduit subscription delivers to, keyed
+// by subscription id, following pagination.
+//
+// The helix client does not decode transport.conduit_id, so subscriptions are listed here.
+func (m *ConduitManager) subscriptionConduits(ctx context.Context) (map[string]string, error) {
+	conduits := make(map[string]string)
+	query := url.Values{}
+
+	for {
+		var out struct {
+			Data []struct {
+				ID        string    `json:"id"`
+				Transport Transport `json:"transport"`
+			} `json:"data"`
+			Pagination helix.Pagination `json:"pagination"`
+		}
+		if err := m.do(ctx, http.MethodGet, "/eventsub/subscriptions", query, nil, &out); err != nil {
+			return nil, fmt.Errorf("failed listing conduit subscriptions: %w", err)
+		}
+
+		for _, sub := range out.Data {
+			if sub.Transport.Method == Conduit {
+				conduits[sub.ID] = sub.Transport.ConduitID
+			}
+		}
+
+		if out.Pagination.Cursor == "" {
+			return conduits, nil
+		}
+		query.Set("after", out.Pagination.Cursor)
+	}
+}
+
+// do performs a Helix request authorized with the app access token.
+func (m *ConduitManager) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed marshalling request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	u := m.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return fmt.Errorf("failed building request: %w", err)
+	}
+	req.Header.Set("Client-Id", m.bot.config.ClientID)
+	req.Header.Set("Authorization", "Bearer "+m.api.GetAppAccessToken())
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed reading response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr helix.ResponseCommon
+		_ = json.Unmarshal(data, &apiErr)
+		return fmt.Errorf("%d %s", resp.StatusCode, apiErr.ErrorMessage)
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed unmarshalling response: %w", err)
+		}
+	}
+	return nil
+}
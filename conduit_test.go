@@ -0,0 +1,213 @@
+package twitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/nicklaw5/helix/v2"
+)
+
+// shardUpdateRequest is the body of a request updating conduit shards.
+type shardUpdateRequest struct {
+	ConduitID string `json:"conduit_id"`
+	Shards    []struct {
+		ID        string                 `json:"id"`
+		Transport map[string]interface{} `json:"transport"`
+	} `json:"shards"`
+}
+
+// serveShardUpdates answers shard updates on f, sending each request to updates.
+func serveShardUpdates(f *fakeHelix, updates chan<- shardUpdateRequest) {
+	f.handle("PATCH /eventsub/conduits/shards", func(w http.ResponseWriter, r *http.Request) {
+		var update shardUpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		updates <- update
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{"data": update.Shards, "errors": []interface{}{}})
+	})
+}
+
+func TestUpdateShardsReturnsShardErrors(t *testing.T) {
+	b := newTestBot(t, nil)
+	f := newFakeHelix(t)
+	f.handle("PATCH /eventsub/conduits/shards", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{
+			"data": []interface{}{},
+			"errors": []ConduitShardError{
+				{ID: "1", Message: "The websocket session is not connected.", Code: "websocket_disconnected"},
+			},
+		})
+	})
+
+	m := NewConduitManager(b, f.client(), f.URL)
+	errs, err := m.UpdateShards(context.Background(), "conduit-1", []ConduitShard{{
+		ID:        "1",
+		Transport: ConduitTransport{Method: Websocket, SessionID: "session-1"},
+	}})
+	if err != nil {
+		t.Fatalf("UpdateShards: %v", err)
+	}
+	if len(errs) != 1 || errs[0].ID != "1" || errs[0].Code != "websocket_disconnected" {
+		t.Errorf("shard errors = %+v, want shard 1 websocket_disconnected", errs)
+	}
+
+	err = m.AssignWebsocketShard(context.Background(), "conduit-1", "1", "session-1")
+	if err == nil || !strings.Contains(err.Error(), "websocket_disconnected") {
+		t.Errorf("AssignWebsocketShard: %v, want the shard error", err)
+	}
+
+	requests := f.received("PATCH /eventsub/conduits/shards")
+	if len(requests) == 0 {
+		t.Fatal("no shard update requested")
+	}
+	r := requests[0]
+	var update shardUpdateRequest
+	if err := json.Unmarshal(r.Body, &update); err != nil {
+		t.Fatalf("decoding shard update: %v", err)
+	}
+	if update.ConduitID != "conduit-1" || len(update.Shards) != 1 || update.Shards[0].Transport["session_id"] != "session-1" {
+		t.Errorf("shard update = %+v, want shard 1 of conduit-1 assigned to session-1", update)
+	}
+}
+
+func TestUpdateShardsReportsRequestErrors(t *testing.T) {
+	b := newTestBot(t, nil)
+	f := newFakeHelix(t)
+	f.handle("PATCH /eventsub/conduits/shards", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{
+			"error":   "Not Found",
+			"status":  http.StatusNotFound,
+			"message": "conduit not found",
+		})
+	})
+
+	m := NewConduitManager(b, f.client(), f.URL)
+	errs, err := m.UpdateShards(context.Background(), "missing", []ConduitShard{{
+		ID:        "0",
+		Transport: ConduitTransport{Method: Webhook, Callback: testCallback, Secret: "0123456789"},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "404 conduit not found") {
+		t.Errorf("UpdateShards: %v, want the 404 reported", err)
+	}
+	if errs != nil {
+		t.Errorf("shard errors = %+v, want none alongside an error", errs)
+	}
+}
+
+func TestShardDisabledReassignsBoundShard(t *testing.T) {
+	b := newTestBot(t, nil)
+	f := newFakeHelix(t)
+	updates := make(chan shardUpdateRequest, 4)
+	serveShardUpdates(f, updates)
+
+	server := newWSServer(t, func(conn *websocket.Conn, n int) {
+		wsWelcome(t, conn, "session-1", 10)
+		wsHold(conn)
+	})
+	client := NewWebsocketClient(b, server.URL())
+	b.websocket = client
+
+	m := NewConduitManager(b, f.client(), f.URL)
+	m.BindWebsocketShard("conduit-1", "0")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- client.Run(ctx) }()
+	defer func() {
+		cancel()
+		receive(t, done, "Run to return")
+	}()
+
+	if update := receive(t, updates, "shard assigned on welcome"); update.Shards[0].Transport["session_id"] != "session-1" {
+		t.Fatalf("shard update = %+v, want session-1", update)
+	}
+
+	// shards that are not bound, or belong to another conduit, are left to their owners
+	m.handleShardDisabled(ctx, EventSubConduitShardDisabledEvent{ConduitID: "conduit-1", ShardID: "1", Status: "websocket_disconnected"})
+	m.handleShardDisabled(ctx, EventSubConduitShardDisabledEvent{ConduitID: "conduit-2", ShardID: "0", Status: "websocket_disconnected"})
+
+	m.handleShardDisabled(ctx, EventSubConduitShardDisabledEvent{
+		ConduitID: "conduit-1",
+		ShardID:   "0",
+		Status:    "websocket_disconnected",
+		Transport: ConduitTransport{Method: Websocket, SessionID: "session-0"},
+	})
+
+	update := receive(t, updates, "shard reassigned after being disabled")
+	if update.ConduitID != "conduit-1" || update.Shards[0].ID != "0" || update.Shards[0].Transport["session_id"] != "session-1" {
+		t.Errorf("shard update = %+v, want shard 0 of conduit-1 reassigned to session-1", update)
+	}
+	if n := len(f.received("PATCH /eventsub/conduits/shards")); n != 2 {
+		t.Errorf("shard updates = %d, want 2", n)
+	}
+}
+
+func TestReconcileMatchesConduitSubscriptionsByConduit(t *testing.T) {
+	b := newTestBot(t, nil)
+	f := newFakeHelix(t)
+
+	subscription := func(id, conduitID string) map[string]interface{} {
+		return map[string]interface{}{
+			"id":        id,
+			"status":    helix.EventSubStatusEnabled,
+			"type":      "stream.online",
+			"version":   "1",
+			"condition": map[string]string{"broadcaster_user_id": "1234"},
+			"transport": map[string]string{"method": string(Conduit), "conduit_id": conduitID},
+		}
+	}
+	f.handle("GET /eventsub/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data":       []interface{}{subscription("sub-a", "conduit-a"), subscription("sub-b", "conduit-b")},
+			"pagination": map[string]string{},
+		})
+	})
+	f.handle("POST /eventsub/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{"data": []interface{}{}})
+	})
+
+	b.conduits = NewConduitManager(b, f.client(), f.URL)
+	m := NewSubscriptionManager(b, f.client(), testCallback)
+
+	spec := func(conduitID string) SubscriptionSpec {
+		return SubscriptionSpec{
+			Type:      "stream.online",
+			Version:   "1",
+			Condition: helix.EventSubCondition{BroadcasterUserID: "1234"},
+			Transport: Transport{Method: Conduit, ConduitID: conduitID},
+		}
+	}
+	m.Declare(spec("conduit-b"), spec("conduit-c"))
+
+	if err := m.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	created := f.received("POST /eventsub/subscriptions")
+	if len(created) != 1 {
+		t.Fatalf("created %d subscriptions, want 1", len(created))
+	}
+	var body struct {
+		Transport Transport `json:"transport"`
+	}
+	if err := json.Unmarshal(created[0].Body, &body); err != nil {
+		t.Fatalf("decoding created subscription: %v", err)
+	}
+	if body.Transport.ConduitID != "conduit-c" {
+		t.Errorf("created for conduit %q, want conduit-c", body.Transport.ConduitID)
+	}
+
+	state := m.State()
+	if len(state.Subscriptions) != 1 || state.Subscriptions[0].ID != "sub-b" {
+		t.Errorf("matched %+v, want only sub-b", state.Subscriptions)
+	}
+	if ids := deletedIDs(f); len(ids) != 0 {
+		t.Errorf("deleted %v, want conduit subscriptions left alone", ids)
+	}
+}
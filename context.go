@@ -0,0 +1,64 @@
+package twitchgo
+
+import (
+	"context"
+	"time"
+
+	"github.com/Etwodev/twitchgo/pkg/log"
+)
+
+// ctxKey is a private type used as a key for storing values in context.
+type ctxKey string
+
+// MetadataCtxKey is the context key used to store and retrieve
+// the Metadata of the message being handled.
+var MetadataCtxKey = ctxKey("metadata")
+
+// Metadata describes the EventSub message an event handler was invoked for.
+type Metadata struct {
+	MessageID        string           // the unique message id, used for deduplication
+	MessageType      string           // e.g. "notification" or "revocation"
+	SubscriptionType SubscriptionType // e.g. "channel.chat.message.v1"
+	Timestamp        time.Time        // when Twitch sent the message
+	ReceivedAt       time.Time        // when the bot received the message
+	Transport        Method           // the transport that delivered the message
+}
+
+// MetadataFromContext attempts to retrieve the Metadata of the message being handled
+// from the provided context.Context.
+//
+// Example usage:
+//
+//	if meta, ok := twitchgo.MetadataFromContext(ctx); ok {
+//	    logger.Info().Str("message_id", meta.MessageID).Msg("handling event")
+//	}
+func MetadataFromContext(ctx context.Context) (Metadata, bool) {
+	meta, ok := ctx.Value(MetadataCtxKey).(Metadata)
+	return meta, ok
+}
+
+// eventContext derives a handler context from the bot context, carrying meta and the
+// bot logger, and bounded by the configured eventTimeout.
+//
+// Unlike a request context, it is only cancelled when the bot shuts down or the deadline passes.
+func (b *Bot) eventContext(meta Metadata) (context.Context, context.CancelFunc) {
+	ctx := context.WithValue(b.ctx, MetadataCtxKey, meta)
+	ctx = context.WithValue(ctx, log.LoggerCtxKey, b.logger)
+
+	if timeout := b.config.EventTimeout; timeout > 0 {
+		return context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	}
+	return context.WithCancel(ctx)
+}
+
+// dispatch queues fn to run with a handler context for meta, after any earlier events sharing key.
+//
+// ctx bounds only the wait for room in the queue, such as the delivering request's context.
+func (b *Bot) dispatch(ctx context.Context, key string, meta Metadata, fn func(ctx context.Context) error) {
+	if !b.dispatcher.enqueue(ctx, key, meta, fn) {
+		b.logger.Warn().
+			Str("message_id", meta.MessageID).
+			Str("subscription", string(meta.SubscriptionType)).
+			Msg("event queue unavailable, dropping event")
+	}
+}
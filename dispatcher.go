@@ -0,0 +1,296 @@
+package twitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// Overflow policies for a full event queue.
+const (
+	OverflowBlock = "block" // wait for room in the queue
+	OverflowDrop  = "drop"  // drop the event and count it
+)
+
+// KeyFunc returns the ordering key of a notification.
+//
+// Events sharing a key are handled one at a time, in the order they were received.
+type KeyFunc func(subscription Subscription[json.RawMessage], event json.RawMessage) string
+
+// DispatcherStats reports the activity of the event dispatcher.
+type DispatcherStats struct {
+	Workers   int    // number of workers
+	Queued    int    // events waiting for a worker
+	Enqueued  uint64 // events accepted since start
+	Processed uint64 // events handled since start
+	Dropped   uint64 // events dropped because their queue was full or the dispatcher stopped
+	Failed    uint64 // handlers that returned an error or panicked
+	Panicked  uint64 // handlers that panicked
+}
+
+// HandlerErrorHandler is optionally implemented by an EventEngine to be told when
+// an event handler returns an error or panics.
+type HandlerErrorHandler interface {
+	// OnHandlerError is called with the metadata of the event whose handler failed.
+	// A panic is reported as a *PanicError.
+	OnHandlerError(ctx context.Context, event Metadata, err error)
+}
+
+// PanicError is the error reported when an event handler panics.
+type PanicError struct {
+	Value interface{} // the value passed to panic
+	Stack []byte      // the stack of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("handler panicked: %v", e.Value)
+}
+
+// job is a handler call waiting for a worker.
+type job struct {
+	meta Metadata
+	fn   func(ctx context.Context) error
+}
+
+// dispatcher runs event handlers on a fixed set of workers.
+//
+// Each worker owns a queue, and events are routed to a worker by their key so that
+// events sharing a key keep their order.
+type dispatcher struct {
+	bot       *Bot
+	queues    []chan job
+	overflow  string
+	keyFn     atomic.Value
+	mu        sync.RWMutex
+	closed    bool
+	stopping  chan struct{} // closed when drain starts, releasing blocked enqueues
+	stop      sync.Once
+	wg        sync.WaitGroup
+	enqueued  atomic.Uint64
+	processed atomic.Uint64
+	dropped   atomic.Uint64
+	failed    atomic.Uint64
+	panicked  atomic.Uint64
+}
+
+func newDispatcher(b *Bot, workers, queueSize int, overflow string) *dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	d := &dispatcher{
+		bot:      b,
+		queues:   make([]chan job, workers),
+		overflow: overflow,
+		stopping: make(chan struct{}),
+	}
+	d.keyFn.Store(KeyFunc(broadcasterKey))
+
+	for i := range d.queues {
+		d.queues[i] = make(chan job, queueSize)
+		d.wg.Add(1)
+		go d.work(d.queues[i])
+	}
+	return d
+}
+
+func (d *dispatcher) work(queue chan job) {
+	defer d.wg.Done()
+	for j := range queue {
+		d.run(j)
+		d.processed.Add(1)
+	}
+}
+
+// run calls a handler, recovering from panics and reporting any failure.
+func (d *dispatcher) run(j job) {
+	ctx, cancel := d.bot.eventContext(j.meta)
+	defer cancel()
+
+	defer func() {
+		if v := recover(); v != nil {
+			d.fail(ctx, j.meta, &PanicError{Value: v, Stack: debug.Stack()})
+		}
+	}()
+
+	if err := j.fn(ctx); err != nil {
+		d.fail(ctx, j.meta, err)
+	}
+}
+
+// fail logs a handler failure and reports it to the engine.
+func (d *dispatcher) fail(ctx context.Context, meta Metadata, err error) {
+	d.failed.Add(1)
+
+	event := d.bot.logger.Error().
+		Err(err).
+		Str("message_id", meta.MessageID).
+		Str("subscription", string(meta.SubscriptionType))
+
+	var perr *PanicError
+	if errors.As(err, &perr) {
+		d.panicked.Add(1)
+		event = event.Str("stack", string(perr.Stack))
+	}
+	event.Msg("event handler failed")
+
+	// the hook may panic too, and must not take down the worker
+	defer func() {
+		if v := recover(); v != nil {
+			d.bot.logger.Error().
+				Str("panic", fmt.Sprint(v)).
+				Str("stack", string(debug.Stack())).
+				Msg("OnHandlerError panicked")
+		}
+	}()
+	d.bot.engine.OnHandlerError(ctx, meta, err)
+}
+
+// key returns the ordering key of a notification using the configured KeyFunc.
+func (d *dispatcher) key(subscription Subscription[json.RawMessage], event json.RawMessage) string {
+	return d.keyFn.Load().(KeyFunc)(subscription, event)
+}
+
+// enqueue queues fn on the worker owning key, reporting whether it was accepted.
+//
+// An empty key spreads events across workers by message id. With the block policy it
+// waits for room until ctx is done or the dispatcher is drained, dropping the event.
+func (d *dispatcher) enqueue(ctx context.Context, key string, meta Metadata, fn func(ctx context.Context) error) bool {
+	if key == "" {
+		key = meta.MessageID
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		d.dropped.Add(1)
+		return false
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	queue := d.queues[h.Sum32()%uint32(len(d.queues))]
+	j := job{meta: meta, fn: fn}
+
+	if d.overflow == OverflowDrop {
+		select {
+		case queue <- j:
+		default:
+			d.dropped.Add(1)
+			return false
+		}
+	} else {
+		select {
+		case queue <- j:
+		case <-ctx.Done():
+			d.dropped.Add(1)
+			return false
+		case <-d.stopping:
+			d.dropped.Add(1)
+			return false
+		}
+	}
+
+	d.enqueued.Add(1)
+	return true
+}
+
+// drain stops accepting events and waits for queued events to be handled or ctx to be done.
+func (d *dispatcher) drain(ctx context.Context) error {
+	d.stop.Do(func() { close(d.stopping) })
+
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, queue := range d.queues {
+			close(queue)
+		}
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *dispatcher) stats() DispatcherStats {
+	queued := 0
+	for _, queue := range d.queues {
+		queued += len(queue)
+	}
+	return DispatcherStats{
+		Workers:   len(d.queues),
+		Queued:    queued,
+		Enqueued:  d.enqueued.Load(),
+		Processed: d.processed.Load(),
+		Dropped:   d.dropped.Load(),
+		Failed:    d.failed.Load(),
+		Panicked:  d.panicked.Load(),
+	}
+}
+
+// broadcasterKey is the default KeyFunc, ordering events by broadcaster.
+//
+// The broadcaster is taken from the event, falling back to the subscription condition.
+func broadcasterKey(subscription Subscription[json.RawMessage], event json.RawMessage) string {
+	var ids struct {
+		BroadcasterUserID   string `json:"broadcaster_user_id"`
+		ToBroadcasterUserID string `json:"to_broadcaster_user_id"`
+	}
+
+	for _, raw := range []json.RawMessage{event, subscription.Condition} {
+		if len(raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(raw, &ids); err != nil {
+			continue
+		}
+		if ids.BroadcasterUserID != "" {
+			return ids.BroadcasterUserID
+		}
+		if ids.ToBroadcasterUserID != "" {
+			return ids.ToBroadcasterUserID
+		}
+	}
+	return ""
+}
+
+// SetKeyFunc sets how notifications are grouped for ordering, replacing the default
+// of one group per broadcaster.
+//
+// Example:
+//
+//	bot.SetKeyFunc(func(s twitchgo.Subscription[json.RawMessage], event json.RawMessage) string {
+//	    return s.Type
+//	})
+func (b *Bot) SetKeyFunc(fn KeyFunc) {
+	b.dispatcher.keyFn.Store(fn)
+}
+
+// DispatcherStats returns the activity of the event dispatcher.
+//
+// Example:
+//
+//	stats := bot.DispatcherStats()
+//	logger.Info().Int("dropped", int(stats.Dropped)).Msg("dispatcher stats")
+func (b *Bot) DispatcherStats() DispatcherStats {
+	return b.dispatcher.stats()
+}
@@ -0,0 +1,220 @@
+package twitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Etwodev/twitchgo/pkg/config"
+	"github.com/Etwodev/twitchgo/pkg/log"
+	"github.com/nicklaw5/helix/v2"
+)
+
+// fullDispatcher returns a dispatcher with one worker, busy until release is closed,
+// and a full queue.
+func fullDispatcher(t *testing.T, overflow string) (d *dispatcher, release chan struct{}) {
+	t.Helper()
+
+	b := newTestBot(t, nil)
+	d = newDispatcher(b, 1, 1, overflow)
+	release = make(chan struct{})
+
+	started := make(chan struct{})
+	wait := func(ctx context.Context) error {
+		<-release
+		return nil
+	}
+	d.enqueue(context.Background(), "key", Metadata{MessageID: "busy"}, func(ctx context.Context) error {
+		close(started)
+		return wait(ctx)
+	})
+	receive(t, started, "worker to start")
+	d.enqueue(context.Background(), "key", Metadata{MessageID: "queued"}, wait)
+
+	return d, release
+}
+
+func TestEnqueueBlockStopsWhenContextDone(t *testing.T) {
+	d, release := fullDispatcher(t, OverflowBlock)
+	defer func() {
+		close(release)
+		_ = d.drain(context.Background())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if d.enqueue(ctx, "key", Metadata{MessageID: "late"}, func(ctx context.Context) error { return nil }) {
+		t.Fatal("enqueue accepted an event into a full queue")
+	}
+	if stats := d.stats(); stats.Dropped != 1 || stats.Enqueued != 2 {
+		t.Errorf("stats = %+v, want 1 dropped and 2 enqueued", stats)
+	}
+}
+
+func TestDrainReleasesBlockedEnqueue(t *testing.T) {
+	d, release := fullDispatcher(t, OverflowBlock)
+
+	accepted := make(chan bool, 1)
+	go func() {
+		accepted <- d.enqueue(context.Background(), "key", Metadata{MessageID: "late"}, func(ctx context.Context) error { return nil })
+	}()
+
+	// let the enqueue block on the full queue before draining
+	time.Sleep(50 * time.Millisecond)
+
+	drained := make(chan error, 1)
+	go func() { drained <- d.drain(context.Background()) }()
+
+	if receive(t, accepted, "blocked enqueue to return") {
+		t.Error("enqueue accepted an event while draining")
+	}
+
+	close(release)
+	if err := receive(t, drained, "drain"); err != nil {
+		t.Errorf("drain: %v", err)
+	}
+	if stats := d.stats(); stats.Processed != 2 || stats.Dropped != 1 {
+		t.Errorf("stats = %+v, want 2 processed and 1 dropped", stats)
+	}
+}
+
+func TestPanickingHandlerIsRecovered(t *testing.T) {
+	engine := &handlerErrorEngine{errs: make(chan error, 1)}
+	b := newTestBot(t, engine)
+
+	handled := make(chan string, 1)
+	RegisterHandler(b, "stream.online", "1", func(ctx context.Context, api *helix.Client, r Response[helix.EventSubStreamOnlineEvent, helix.EventSubCondition]) error {
+		if r.Event.BroadcasterUserLogin == "panic" {
+			panic("boom")
+		}
+		handled <- r.Event.BroadcasterUserLogin
+		return nil
+	})
+
+	// both events share a broadcaster, so the second runs on the worker that panicked
+	for i, login := range []string{"panic", "streamer"} {
+		body := `{"subscription":{"id":"sub-1","type":"stream.online","version":"1","condition":{"broadcaster_user_id":"1234"}},` +
+			`"event":{"broadcaster_user_id":"1234","broadcaster_user_login":"` + login + `"}}`
+		if w := postWebhook(t, b, "message-"+strconv.Itoa(i), "notification", body); w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+	}
+
+	err := receive(t, engine.errs, "OnHandlerError")
+	var perr *PanicError
+	if !errors.As(err, &perr) || perr.Value != "boom" || len(perr.Stack) == 0 {
+		t.Errorf("OnHandlerError got %v, want a *PanicError for boom with its stack", err)
+	}
+
+	if login := receive(t, handled, "event after the panic"); login != "streamer" {
+		t.Errorf("handled %q, want streamer", login)
+	}
+
+	if err := b.dispatcher.drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if stats := b.dispatcher.stats(); stats.Processed != 2 || stats.Failed != 1 || stats.Panicked != 1 {
+		t.Errorf("stats = %+v, want 2 processed, 1 failed and 1 panicked", stats)
+	}
+}
+
+func TestEnqueueDropWhenFull(t *testing.T) {
+	d, release := fullDispatcher(t, OverflowDrop)
+	defer func() {
+		close(release)
+		_ = d.drain(context.Background())
+	}()
+
+	// without a deadline, the block policy would wait here for the worker
+	if d.enqueue(context.Background(), "key", Metadata{MessageID: "late"}, func(ctx context.Context) error { return nil }) {
+		t.Fatal("enqueue accepted an event into a full queue")
+	}
+	if stats := d.stats(); stats.Dropped != 1 || stats.Enqueued != 2 || stats.Queued != 1 {
+		t.Errorf("stats = %+v, want 1 dropped, 2 enqueued and 1 queued", stats)
+	}
+}
+
+func TestDispatcherKeepsOrderPerKey(t *testing.T) {
+	b := newTestBot(t, nil)
+	d := newDispatcher(b, 4, 64, OverflowBlock)
+
+	var mu sync.Mutex
+	handled := make(map[string][]int)
+	for i := 0; i < 50; i++ {
+		for _, key := range []string{"a", "b", "c"} {
+			d.enqueue(context.Background(), key, Metadata{MessageID: key + strconv.Itoa(i)}, func(ctx context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				handled[key] = append(handled[key], i)
+				return nil
+			})
+		}
+	}
+
+	if err := d.drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	for key, order := range handled {
+		if len(order) != 50 {
+			t.Errorf("key %s handled %d events, want 50", key, len(order))
+		}
+		for i, n := range order {
+			if n != i {
+				t.Errorf("key %s handled event %d at position %d, want in order", key, n, i)
+				break
+			}
+		}
+	}
+}
+
+func TestSetKeyFunc(t *testing.T) {
+	b := newTestBot(t, nil)
+
+	keyed := make(chan string, 1)
+	b.SetKeyFunc(func(s Subscription[json.RawMessage], event json.RawMessage) string {
+		keyed <- s.Type
+		return s.Type
+	})
+
+	handled := make(chan struct{}, 1)
+	RegisterHandler(b, "stream.online", "1", func(ctx context.Context, api *helix.Client, r Response[helix.EventSubStreamOnlineEvent, helix.EventSubCondition]) error {
+		handled <- struct{}{}
+		return nil
+	})
+
+	if w := postWebhook(t, b, "message-1", "notification", streamOnlineBody("streamer")); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if typ := receive(t, keyed, "KeyFunc"); typ != "stream.online" {
+		t.Errorf("KeyFunc called for %q, want stream.online", typ)
+	}
+	receive(t, handled, "handler")
+
+	sub := Subscription[json.RawMessage]{Type: "channel.follow", Condition: json.RawMessage(`{"broadcaster_user_id":"1234"}`)}
+	if key := b.dispatcher.key(sub, nil); key != "channel.follow" {
+		t.Errorf("key = %q, want the type from the KeyFunc", key)
+	}
+}
+
+func TestEventOverflowValidated(t *testing.T) {
+	for _, overflow := range []string{OverflowBlock, OverflowDrop} {
+		cfg := config.Default()
+		cfg.EventOverflow = overflow
+		newTestBot(t, nil, WithConfig(cfg))
+	}
+
+	for _, overflow := range []string{"Drop", "", "discard"} {
+		cfg := config.Default()
+		cfg.EventOverflow = overflow
+		if _, err := NewWithOptions(nil, WithConfig(cfg), WithLogger(&log.NoOpLogger{})); err == nil || !strings.Contains(err.Error(), "eventOverflow") {
+			t.Errorf("NewWithOptions with eventOverflow %q: %v, want it rejected", overflow, err)
+		}
+	}
+}
@@ -2,6 +2,9 @@ package twitchgo
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
 
 	"github.com/nicklaw5/helix/v2"
 )
@@ -10,9 +13,69 @@ type SubscriptionType string
 
 const (
 	ChannelChatMessage SubscriptionType = "channel.chat.message.v1"
+	ChannelUpdate      SubscriptionType = "channel.update.v2"
+	StreamOnline       SubscriptionType = "stream.online.v1"
+	StreamOffline      SubscriptionType = "stream.offline.v1"
+
+	ChannelSubscribe           SubscriptionType = "channel.subscribe.v1"
+	ChannelSubscriptionMessage SubscriptionType = "channel.subscription.message.v1"
+	ChannelSubscriptionGift    SubscriptionType = "channel.subscription.gift.v1"
+	ChannelSubscriptionEnd     SubscriptionType = "channel.subscription.end.v1"
+	ChannelCheer               SubscriptionType = "channel.cheer.v1"
+
+	ChannelPointsCustomRewardAdd              SubscriptionType = "channel.channel_points_custom_reward.add.v1"
+	ChannelPointsCustomRewardUpdate           SubscriptionType = "channel.channel_points_custom_reward.update.v1"
+	ChannelPointsCustomRewardRemove           SubscriptionType = "channel.channel_points_custom_reward.remove.v1"
+	ChannelPointsCustomRewardRedemptionAdd    SubscriptionType = "channel.channel_points_custom_reward_redemption.add.v1"
+	ChannelPointsCustomRewardRedemptionUpdate SubscriptionType = "channel.channel_points_custom_reward_redemption.update.v1"
+	ChannelPointsAutomaticRewardRedemptionAdd SubscriptionType = "channel.channel_points_automatic_reward_redemption.add.v2"
+
+	ChannelBan                 SubscriptionType = "channel.ban.v1"
+	ChannelUnban               SubscriptionType = "channel.unban.v1"
+	ChannelModerate            SubscriptionType = "channel.moderate.v2"
+	ChannelWarningSend         SubscriptionType = "channel.warning.send.v1"
+	ChannelWarningAcknowledge  SubscriptionType = "channel.warning.acknowledge.v1"
+	ChannelUnbanRequestCreate  SubscriptionType = "channel.unban_request.create.v1"
+	ChannelUnbanRequestResolve SubscriptionType = "channel.unban_request.resolve.v1"
+	ChannelModeratorAdd        SubscriptionType = "channel.moderator.add.v1"
+	ChannelModeratorRemove     SubscriptionType = "channel.moderator.remove.v1"
+	ChannelVIPAdd              SubscriptionType = "channel.vip.add.v1"
+	ChannelVIPRemove           SubscriptionType = "channel.vip.remove.v1"
+
+	ChannelPollBegin               SubscriptionType = "channel.poll.begin.v1"
+	ChannelPollProgress            SubscriptionType = "channel.poll.progress.v1"
+	ChannelPollEnd                 SubscriptionType = "channel.poll.end.v1"
+	ChannelPredictionBegin         SubscriptionType = "channel.prediction.begin.v1"
+	ChannelPredictionProgress      SubscriptionType = "channel.prediction.progress.v1"
+	ChannelPredictionLock          SubscriptionType = "channel.prediction.lock.v1"
+	ChannelPredictionEnd           SubscriptionType = "channel.prediction.end.v1"
+	ChannelHypeTrainBegin          SubscriptionType = "channel.hype_train.begin.v1"
+	ChannelHypeTrainProgress       SubscriptionType = "channel.hype_train.progress.v1"
+	ChannelHypeTrainEnd            SubscriptionType = "channel.hype_train.end.v1"
+	ChannelGoalBegin               SubscriptionType = "channel.goal.begin.v1"
+	ChannelGoalProgress            SubscriptionType = "channel.goal.progress.v1"
+	ChannelGoalEnd                 SubscriptionType = "channel.goal.end.v1"
+	ChannelCharityCampaignStart    SubscriptionType = "channel.charity_campaign.start.v1"
+	ChannelCharityCampaignProgress SubscriptionType = "channel.charity_campaign.progress.v1"
+	ChannelCharityCampaignStop     SubscriptionType = "channel.charity_campaign.stop.v1"
+	ChannelCharityCampaignDonate   SubscriptionType = "channel.charity_campaign.donate.v1"
+
+	ChannelChatNotification      SubscriptionType = "channel.chat.notification.v1"
+	ChannelChatMessageDelete     SubscriptionType = "channel.chat.message_delete.v1"
+	ChannelChatClear             SubscriptionType = "channel.chat.clear.v1"
+	ChannelChatClearUserMessages SubscriptionType = "channel.chat.clear_user_messages.v1"
+	ChannelChatSettingsUpdate    SubscriptionType = "channel.chat_settings.update.v1"
+	AutomodMessageHold           SubscriptionType = "automod.message.hold.v1"
+	AutomodMessageUpdate         SubscriptionType = "automod.message.update.v1"
+
+	ConduitShardDisabled SubscriptionType = "conduit.shard.disabled.v1"
 )
 
-// EventEngine defines an interface for handling various Twitch bot events.
+// EventEngine defines the lifecycle callbacks of a Twitch bot.
+//
+// Event callbacks are optional: an engine receives an event by implementing its handler
+// interface, such as ChatMessageHandler or StreamOnlineHandler. Embed BaseEngine to
+// implement the lifecycle callbacks as no-ops.
 type EventEngine interface {
 	// OnBotStart is called when the bot starts.
 	// Useful for initializing connections, registering event subscriptions, or performing startup routines.
@@ -25,7 +88,556 @@ type EventEngine interface {
 	// OnClientRefresh is called whenever an access token is refreshed.
 	// This ensures the bot continues to operate with a valid token without interruption.
 	OnClientRefresh(ctx context.Context, api *helix.Client)
+}
+
+// BaseEngine implements the EventEngine lifecycle callbacks as no-ops.
+//
+// Embed it to implement only the callbacks you need.
+//
+// Example:
+//
+//	type MyEngine struct {
+//	    twitchgo.BaseEngine
+//	}
+//
+//	func (e *MyEngine) OnChannelChatMessage(ctx context.Context, api *helix.Client, r twitchgo.Response[helix.EventSubChannelChatMessageEvent, helix.EventSubCondition]) error {
+//	    return nil
+//	}
+type BaseEngine struct{}
+
+// OnBotStart does nothing.
+func (BaseEngine) OnBotStart(ctx context.Context, api *helix.Client) {}
 
-	
+// OnClientLogin does nothing.
+func (BaseEngine) OnClientLogin(ctx context.Context, api *helix.Client) {}
+
+// OnClientRefresh does nothing.
+func (BaseEngine) OnClientRefresh(ctx context.Context, api *helix.Client) {}
+
+// ReadyHandler is implemented by engines that handle OnReady.
+type ReadyHandler interface {
+	// OnReady is called once the server is accepting connections, so webhook
+	// verification challenges can be answered. ctx is cancelled when the bot stops.
+	OnReady(ctx context.Context, api *helix.Client)
+}
+
+// BotStopHandler is implemented by engines that handle OnBotStop.
+type BotStopHandler interface {
+	// OnBotStop is called when the bot is shutting down, before the server stops.
+	// ctx expires after the configured shutdownTimeout.
+	OnBotStop(ctx context.Context, api *helix.Client)
+}
+
+// TokenInvalidHandler is implemented by engines that handle OnTokenInvalid.
+type TokenInvalidHandler interface {
+	// OnTokenInvalid is called when a user's token is rejected and cannot be refreshed,
+	// usually because the user disconnected the app. The token has been removed from
+	// the store, so the user must log in again.
+	OnTokenInvalid(ctx context.Context, userID, reason string)
+}
+
+// SubscriptionRevokedHandler is implemented by engines that handle OnSubscriptionRevoked.
+type SubscriptionRevokedHandler interface {
+	// OnSubscriptionRevoked is called when Twitch revokes a subscription.
+	// The subscription's Status holds the reason, such as AuthorizationRevoked or UserRemoved.
+	OnSubscriptionRevoked(ctx context.Context, api *helix.Client, subscription Subscription[helix.EventSubCondition]) error
+}
+
+// ChatMessageHandler is implemented by engines that handle OnChannelChatMessage.
+type ChatMessageHandler interface {
+	// OnChannelChatMessage is called when a message is sent to a channel's chat.
+	OnChannelChatMessage(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelChatMessageEvent, helix.EventSubCondition]) error
+}
+
+// legacyChatMessageHandler is implemented by engines written against the EventEngine
+// interface of earlier versions, whose OnChannelChatMessage returned nothing.
+type legacyChatMessageHandler interface {
 	OnChannelChatMessage(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelChatMessageEvent, helix.EventSubCondition])
 }
+
+// ChannelUpdateHandler is implemented by engines that handle OnChannelUpdate.
+type ChannelUpdateHandler interface {
+	// OnChannelUpdate is called when a broadcaster updates their channel's title, category, language or labels.
+	OnChannelUpdate(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelUpdateEvent, helix.EventSubCondition]) error
+}
+
+// StreamOnlineHandler is implemented by engines that handle OnStreamOnline.
+type StreamOnlineHandler interface {
+	// OnStreamOnline is called when a broadcaster goes live.
+	OnStreamOnline(ctx context.Context, api *helix.Client, response Response[helix.EventSubStreamOnlineEvent, helix.EventSubCondition]) error
+}
+
+// StreamOfflineHandler is implemented by engines that handle OnStreamOffline.
+type StreamOfflineHandler interface {
+	// OnStreamOffline is called when a broadcaster stops streaming.
+	OnStreamOffline(ctx context.Context, api *helix.Client, response Response[helix.EventSubStreamOfflineEvent, helix.EventSubCondition]) error
+}
+
+// SubscribeHandler is implemented by engines that handle OnChannelSubscribe.
+type SubscribeHandler interface {
+	// OnChannelSubscribe is called when a user subscribes to a channel, excluding resubscriptions.
+	OnChannelSubscribe(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelSubscribeEvent, helix.EventSubCondition]) error
+}
+
+// SubscriptionMessageHandler is implemented by engines that handle OnChannelSubscriptionMessage.
+type SubscriptionMessageHandler interface {
+	// OnChannelSubscriptionMessage is called when a user shares a resubscription message in chat.
+	OnChannelSubscriptionMessage(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelSubscriptionMessageEvent, helix.EventSubCondition]) error
+}
+
+// SubscriptionGiftHandler is implemented by engines that handle OnChannelSubscriptionGift.
+type SubscriptionGiftHandler interface {
+	// OnChannelSubscriptionGift is called when a user gifts one or more subscriptions.
+	OnChannelSubscriptionGift(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelSubscriptionGiftEvent, helix.EventSubCondition]) error
+}
+
+// SubscriptionEndHandler is implemented by engines that handle OnChannelSubscriptionEnd.
+type SubscriptionEndHandler interface {
+	// OnChannelSubscriptionEnd is called when a subscription to a channel expires.
+	OnChannelSubscriptionEnd(ctx context.Context, api *helix.Client, response Response[EventSubChannelSubscriptionEndEvent, helix.EventSubCondition]) error
+}
+
+// CheerHandler is implemented by engines that handle OnChannelCheer.
+type CheerHandler interface {
+	// OnChannelCheer is called when a user cheers bits in a channel.
+	OnChannelCheer(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelCheerEvent, helix.EventSubCondition]) error
+}
+
+// ChannelPointsCustomRewardAddHandler is implemented by engines that handle OnChannelPointsCustomRewardAdd.
+type ChannelPointsCustomRewardAddHandler interface {
+	// OnChannelPointsCustomRewardAdd is called when a custom channel points reward is created.
+	OnChannelPointsCustomRewardAdd(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelPointsCustomRewardEvent, helix.EventSubCondition]) error
+}
+
+// ChannelPointsCustomRewardUpdateHandler is implemented by engines that handle OnChannelPointsCustomRewardUpdate.
+type ChannelPointsCustomRewardUpdateHandler interface {
+	// OnChannelPointsCustomRewardUpdate is called when a custom channel points reward is updated.
+	OnChannelPointsCustomRewardUpdate(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelPointsCustomRewardEvent, helix.EventSubCondition]) error
+}
+
+// ChannelPointsCustomRewardRemoveHandler is implemented by engines that handle OnChannelPointsCustomRewardRemove.
+type ChannelPointsCustomRewardRemoveHandler interface {
+	// OnChannelPointsCustomRewardRemove is called when a custom channel points reward is removed.
+	OnChannelPointsCustomRewardRemove(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelPointsCustomRewardEvent, helix.EventSubCondition]) error
+}
+
+// ChannelPointsCustomRewardRedemptionAddHandler is implemented by engines that handle OnChannelPointsCustomRewardRedemptionAdd.
+type ChannelPointsCustomRewardRedemptionAddHandler interface {
+	// OnChannelPointsCustomRewardRedemptionAdd is called when a viewer redeems a custom channel points reward.
+	OnChannelPointsCustomRewardRedemptionAdd(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelPointsCustomRewardRedemptionEvent, helix.EventSubCondition]) error
+}
+
+// ChannelPointsCustomRewardRedemptionUpdateHandler is implemented by engines that handle OnChannelPointsCustomRewardRedemptionUpdate.
+type ChannelPointsCustomRewardRedemptionUpdateHandler interface {
+	// OnChannelPointsCustomRewardRedemptionUpdate is called when a redemption is fulfilled or cancelled.
+	OnChannelPointsCustomRewardRedemptionUpdate(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelPointsCustomRewardRedemptionEvent, helix.EventSubCondition]) error
+}
+
+// ChannelPointsAutomaticRewardRedemptionAddHandler is implemented by engines that handle OnChannelPointsAutomaticRewardRedemptionAdd.
+type ChannelPointsAutomaticRewardRedemptionAddHandler interface {
+	// OnChannelPointsAutomaticRewardRedemptionAdd is called when a viewer redeems an automatic channel points reward.
+	OnChannelPointsAutomaticRewardRedemptionAdd(ctx context.Context, api *helix.Client, response Response[EventSubChannelPointsAutomaticRewardRedemptionEvent, helix.EventSubCondition]) error
+}
+
+// BanHandler is implemented by engines that handle OnChannelBan.
+type BanHandler interface {
+	// OnChannelBan is called when a user is banned or timed out in a channel.
+	OnChannelBan(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelBanEvent, helix.EventSubCondition]) error
+}
+
+// UnbanHandler is implemented by engines that handle OnChannelUnban.
+type UnbanHandler interface {
+	// OnChannelUnban is called when a user is unbanned in a channel.
+	OnChannelUnban(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelUnbanEvent, helix.EventSubCondition]) error
+}
+
+// ModerateHandler is implemented by engines that handle OnChannelModerate.
+type ModerateHandler interface {
+	// OnChannelModerate is called when a moderator performs any moderation action in a channel.
+	OnChannelModerate(ctx context.Context, api *helix.Client, response Response[EventSubChannelModerateEvent, helix.EventSubCondition]) error
+}
+
+// WarningSendHandler is implemented by engines that handle OnChannelWarningSend.
+type WarningSendHandler interface {
+	// OnChannelWarningSend is called when a moderator warns a user.
+	OnChannelWarningSend(ctx context.Context, api *helix.Client, response Response[EventSubChannelWarningSendEvent, helix.EventSubCondition]) error
+}
+
+// WarningAcknowledgeHandler is implemented by engines that handle OnChannelWarningAcknowledge.
+type WarningAcknowledgeHandler interface {
+	// OnChannelWarningAcknowledge is called when a warned user acknowledges their warning.
+	OnChannelWarningAcknowledge(ctx context.Context, api *helix.Client, response Response[EventSubChannelWarningAcknowledgeEvent, helix.EventSubCondition]) error
+}
+
+// UnbanRequestCreateHandler is implemented by engines that handle OnChannelUnbanRequestCreate.
+type UnbanRequestCreateHandler interface {
+	// OnChannelUnbanRequestCreate is called when a banned user submits an unban request.
+	OnChannelUnbanRequestCreate(ctx context.Context, api *helix.Client, response Response[EventSubChannelUnbanRequestCreateEvent, helix.EventSubCondition]) error
+}
+
+// UnbanRequestResolveHandler is implemented by engines that handle OnChannelUnbanRequestResolve.
+type UnbanRequestResolveHandler interface {
+	// OnChannelUnbanRequestResolve is called when an unban request is approved, denied or canceled.
+	OnChannelUnbanRequestResolve(ctx context.Context, api *helix.Client, response Response[EventSubChannelUnbanRequestResolveEvent, helix.EventSubCondition]) error
+}
+
+// ModeratorAddHandler is implemented by engines that handle OnChannelModeratorAdd.
+type ModeratorAddHandler interface {
+	// OnChannelModeratorAdd is called when a user is given moderator privileges.
+	OnChannelModeratorAdd(ctx context.Context, api *helix.Client, response Response[helix.EventSubModeratorAddEvent, helix.EventSubCondition]) error
+}
+
+// ModeratorRemoveHandler is implemented by engines that handle OnChannelModeratorRemove.
+type ModeratorRemoveHandler interface {
+	// OnChannelModeratorRemove is called when a user has moderator privileges removed.
+	OnChannelModeratorRemove(ctx context.Context, api *helix.Client, response Response[helix.EventSubModeratorRemoveEvent, helix.EventSubCondition]) error
+}
+
+// VIPAddHandler is implemented by engines that handle OnChannelVIPAdd.
+type VIPAddHandler interface {
+	// OnChannelVIPAdd is called when a user is given VIP status.
+	OnChannelVIPAdd(ctx context.Context, api *helix.Client, response Response[EventSubChannelVIPEvent, helix.EventSubCondition]) error
+}
+
+// VIPRemoveHandler is implemented by engines that handle OnChannelVIPRemove.
+type VIPRemoveHandler interface {
+	// OnChannelVIPRemove is called when a user has VIP status removed.
+	OnChannelVIPRemove(ctx context.Context, api *helix.Client, response Response[EventSubChannelVIPEvent, helix.EventSubCondition]) error
+}
+
+// PollBeginHandler is implemented by engines that handle OnChannelPollBegin.
+type PollBeginHandler interface {
+	// OnChannelPollBegin is called when a poll begins in a channel.
+	OnChannelPollBegin(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelPollBeginEvent, helix.EventSubCondition]) error
+}
+
+// PollProgressHandler is implemented by engines that handle OnChannelPollProgress.
+type PollProgressHandler interface {
+	// OnChannelPollProgress is called when a viewer votes in an active poll.
+	OnChannelPollProgress(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelPollProgressEvent, helix.EventSubCondition]) error
+}
+
+// PollEndHandler is implemented by engines that handle OnChannelPollEnd.
+type PollEndHandler interface {
+	// OnChannelPollEnd is called when a poll ends in a channel.
+	OnChannelPollEnd(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelPollEndEvent, helix.EventSubCondition]) error
+}
+
+// PredictionBeginHandler is implemented by engines that handle OnChannelPredictionBegin.
+type PredictionBeginHandler interface {
+	// OnChannelPredictionBegin is called when a prediction begins in a channel.
+	OnChannelPredictionBegin(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelPredictionBeginEvent, helix.EventSubCondition]) error
+}
+
+// PredictionProgressHandler is implemented by engines that handle OnChannelPredictionProgress.
+type PredictionProgressHandler interface {
+	// OnChannelPredictionProgress is called when a viewer participates in an active prediction.
+	OnChannelPredictionProgress(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelPredictionProgressEvent, helix.EventSubCondition]) error
+}
+
+// PredictionLockHandler is implemented by engines that handle OnChannelPredictionLock.
+type PredictionLockHandler interface {
+	// OnChannelPredictionLock is called when a prediction is locked.
+	OnChannelPredictionLock(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelPredictionLockEvent, helix.EventSubCondition]) error
+}
+
+// PredictionEndHandler is implemented by engines that handle OnChannelPredictionEnd.
+type PredictionEndHandler interface {
+	// OnChannelPredictionEnd is called when a prediction ends in a channel.
+	OnChannelPredictionEnd(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelPredictionEndEvent, helix.EventSubCondition]) error
+}
+
+// HypeTrainBeginHandler is implemented by engines that handle OnChannelHypeTrainBegin.
+type HypeTrainBeginHandler interface {
+	// OnChannelHypeTrainBegin is called when a hype train begins in a channel.
+	OnChannelHypeTrainBegin(ctx context.Context, api *helix.Client, response Response[helix.EventSubHypeTrainBeginEvent, helix.EventSubCondition]) error
+}
+
+// HypeTrainProgressHandler is implemented by engines that handle OnChannelHypeTrainProgress.
+type HypeTrainProgressHandler interface {
+	// OnChannelHypeTrainProgress is called when a hype train makes progress.
+	OnChannelHypeTrainProgress(ctx context.Context, api *helix.Client, response Response[helix.EventSubHypeTrainProgressEvent, helix.EventSubCondition]) error
+}
+
+// HypeTrainEndHandler is implemented by engines that handle OnChannelHypeTrainEnd.
+type HypeTrainEndHandler interface {
+	// OnChannelHypeTrainEnd is called when a hype train ends.
+	OnChannelHypeTrainEnd(ctx context.Context, api *helix.Client, response Response[helix.EventSubHypeTrainEndEvent, helix.EventSubCondition]) error
+}
+
+// GoalBeginHandler is implemented by engines that handle OnChannelGoalBegin.
+type GoalBeginHandler interface {
+	// OnChannelGoalBegin is called when a broadcaster starts a creator goal.
+	OnChannelGoalBegin(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelGoalStartEvent, helix.EventSubCondition]) error
+}
+
+// GoalProgressHandler is implemented by engines that handle OnChannelGoalProgress.
+type GoalProgressHandler interface {
+	// OnChannelGoalProgress is called when progress is made towards a creator goal.
+	OnChannelGoalProgress(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelGoalProgressEvent, helix.EventSubCondition]) error
+}
+
+// GoalEndHandler is implemented by engines that handle OnChannelGoalEnd.
+type GoalEndHandler interface {
+	// OnChannelGoalEnd is called when a creator goal ends.
+	OnChannelGoalEnd(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelGoalEndEvent, helix.EventSubCondition]) error
+}
+
+// CharityCampaignStartHandler is implemented by engines that handle OnChannelCharityCampaignStart.
+type CharityCampaignStartHandler interface {
+	// OnChannelCharityCampaignStart is called when a broadcaster starts a charity campaign.
+	OnChannelCharityCampaignStart(ctx context.Context, api *helix.Client, response Response[helix.EventSubCharityStartEvent, helix.EventSubCondition]) error
+}
+
+// CharityCampaignProgressHandler is implemented by engines that handle OnChannelCharityCampaignProgress.
+type CharityCampaignProgressHandler interface {
+	// OnChannelCharityCampaignProgress is called when progress is made towards a charity campaign's goal.
+	OnChannelCharityCampaignProgress(ctx context.Context, api *helix.Client, response Response[helix.EventSubCharityProgressEvent, helix.EventSubCondition]) error
+}
+
+// CharityCampaignStopHandler is implemented by engines that handle OnChannelCharityCampaignStop.
+type CharityCampaignStopHandler interface {
+	// OnChannelCharityCampaignStop is called when a broadcaster stops a charity campaign.
+	OnChannelCharityCampaignStop(ctx context.Context, api *helix.Client, response Response[helix.EventSubCharityStopEvent, helix.EventSubCondition]) error
+}
+
+// CharityCampaignDonateHandler is implemented by engines that handle OnChannelCharityCampaignDonate.
+type CharityCampaignDonateHandler interface {
+	// OnChannelCharityCampaignDonate is called when a viewer donates to a charity campaign.
+	OnChannelCharityCampaignDonate(ctx context.Context, api *helix.Client, response Response[helix.EventSubCharityDonationEvent, helix.EventSubCondition]) error
+}
+
+// ChatNotificationHandler is implemented by engines that handle OnChannelChatNotification.
+type ChatNotificationHandler interface {
+	// OnChannelChatNotification is called when an event that appears in chat occurs, such as a sub, raid or announcement.
+	OnChannelChatNotification(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelChatNotificationEvent, helix.EventSubCondition]) error
+}
+
+// ChatMessageDeleteHandler is implemented by engines that handle OnChannelChatMessageDelete.
+type ChatMessageDeleteHandler interface {
+	// OnChannelChatMessageDelete is called when a moderator removes a specific message.
+	OnChannelChatMessageDelete(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelChatMessageDeleteEvent, helix.EventSubCondition]) error
+}
+
+// ChatClearHandler is implemented by engines that handle OnChannelChatClear.
+type ChatClearHandler interface {
+	// OnChannelChatClear is called when a moderator or bot clears all messages from chat.
+	OnChannelChatClear(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelChatClearEvent, helix.EventSubCondition]) error
+}
+
+// ChatClearUserMessagesHandler is implemented by engines that handle OnChannelChatClearUserMessages.
+type ChatClearUserMessagesHandler interface {
+	// OnChannelChatClearUserMessages is called when a moderator or bot clears all messages for a specific user.
+	OnChannelChatClearUserMessages(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelChatClearUserMessagesEvent, helix.EventSubCondition]) error
+}
+
+// ChatSettingsUpdateHandler is implemented by engines that handle OnChannelChatSettingsUpdate.
+type ChatSettingsUpdateHandler interface {
+	// OnChannelChatSettingsUpdate is called when a broadcaster's chat settings are updated.
+	OnChannelChatSettingsUpdate(ctx context.Context, api *helix.Client, response Response[EventSubChannelChatSettingsUpdateEvent, helix.EventSubCondition]) error
+}
+
+// AutomodMessageHoldHandler is implemented by engines that handle OnAutomodMessageHold.
+type AutomodMessageHoldHandler interface {
+	// OnAutomodMessageHold is called when a message is held by automod for review.
+	OnAutomodMessageHold(ctx context.Context, api *helix.Client, response Response[EventSubAutomodMessageHoldEvent, helix.EventSubCondition]) error
+}
+
+// AutomodMessageUpdateHandler is implemented by engines that handle OnAutomodMessageUpdate.
+type AutomodMessageUpdateHandler interface {
+	// OnAutomodMessageUpdate is called when a held message is approved, denied or expires.
+	OnAutomodMessageUpdate(ctx context.Context, api *helix.Client, response Response[EventSubAutomodMessageUpdateEvent, helix.EventSubCondition]) error
+}
+
+// ConduitShardDisabledHandler is implemented by engines that handle OnConduitShardDisabled.
+type ConduitShardDisabledHandler interface {
+	// OnConduitShardDisabled is called when a conduit shard's transport is disabled.
+	// Shards bound with BindWebsocketShard are reassigned before this is called.
+	OnConduitShardDisabled(ctx context.Context, api *helix.Client, response Response[EventSubConduitShardDisabledEvent, helix.EventSubCondition]) error
+}
+
+// registerEngine wires the callbacks implemented by the bot's plugins into its handler registry.
+//
+// Subscription types no plugin handles are left to the fallback handler, and those with a
+// handler registered by RegisterHandler are left to it.
+func registerEngine(b *Bot) {
+	registerChatMessage(b)
+	registerEvent(b, "channel.update", "2", ChannelUpdateHandler.OnChannelUpdate)
+	registerEvent(b, "stream.online", "1", StreamOnlineHandler.OnStreamOnline)
+	registerEvent(b, "stream.offline", "1", StreamOfflineHandler.OnStreamOffline)
+	registerEvent(b, "channel.subscribe", "1", SubscribeHandler.OnChannelSubscribe)
+	registerEvent(b, "channel.subscription.message", "1", SubscriptionMessageHandler.OnChannelSubscriptionMessage)
+	registerEvent(b, "channel.subscription.gift", "1", SubscriptionGiftHandler.OnChannelSubscriptionGift)
+	registerEvent(b, "channel.subscription.end", "1", SubscriptionEndHandler.OnChannelSubscriptionEnd)
+	registerEvent(b, "channel.cheer", "1", CheerHandler.OnChannelCheer)
+	registerEvent(b, "channel.channel_points_custom_reward.add", "1", ChannelPointsCustomRewardAddHandler.OnChannelPointsCustomRewardAdd)
+	registerEvent(b, "channel.channel_points_custom_reward.update", "1", ChannelPointsCustomRewardUpdateHandler.OnChannelPointsCustomRewardUpdate)
+	registerEvent(b, "channel.channel_points_custom_reward.remove", "1", ChannelPointsCustomRewardRemoveHandler.OnChannelPointsCustomRewardRemove)
+	registerEvent(b, "channel.channel_points_custom_reward_redemption.add", "1", ChannelPointsCustomRewardRedemptionAddHandler.OnChannelPointsCustomRewardRedemptionAdd)
+	registerEvent(b, "channel.channel_points_custom_reward_redemption.update", "1", ChannelPointsCustomRewardRedemptionUpdateHandler.OnChannelPointsCustomRewardRedemptionUpdate)
+	registerEvent(b, "channel.channel_points_automatic_reward_redemption.add", "2", ChannelPointsAutomaticRewardRedemptionAddHandler.OnChannelPointsAutomaticRewardRedemptionAdd)
+	registerEvent(b, "channel.ban", "1", BanHandler.OnChannelBan)
+	registerEvent(b, "channel.unban", "1", UnbanHandler.OnChannelUnban)
+	registerEvent(b, "channel.moderate", "2", ModerateHandler.OnChannelModerate)
+	registerEvent(b, "channel.warning.send", "1", WarningSendHandler.OnChannelWarningSend)
+	registerEvent(b, "channel.warning.acknowledge", "1", WarningAcknowledgeHandler.OnChannelWarningAcknowledge)
+	registerEvent(b, "channel.unban_request.create", "1", UnbanRequestCreateHandler.OnChannelUnbanRequestCreate)
+	registerEvent(b, "channel.unban_request.resolve", "1", UnbanRequestResolveHandler.OnChannelUnbanRequestResolve)
+	registerEvent(b, "channel.moderator.add", "1", ModeratorAddHandler.OnChannelModeratorAdd)
+	registerEvent(b, "channel.moderator.remove", "1", ModeratorRemoveHandler.OnChannelModeratorRemove)
+	registerEvent(b, "channel.vip.add", "1", VIPAddHandler.OnChannelVIPAdd)
+	registerEvent(b, "channel.vip.remove", "1", VIPRemoveHandler.OnChannelVIPRemove)
+	registerEvent(b, "channel.poll.begin", "1", PollBeginHandler.OnChannelPollBegin)
+	registerEvent(b, "channel.poll.progress", "1", PollProgressHandler.OnChannelPollProgress)
+	registerEvent(b, "channel.poll.end", "1", PollEndHandler.OnChannelPollEnd)
+	registerEvent(b, "channel.prediction.begin", "1", PredictionBeginHandler.OnChannelPredictionBegin)
+	registerEvent(b, "channel.prediction.progress", "1", PredictionProgressHandler.OnChannelPredictionProgress)
+	registerEvent(b, "channel.prediction.lock", "1", PredictionLockHandler.OnChannelPredictionLock)
+	registerEvent(b, "channel.prediction.end", "1", PredictionEndHandler.OnChannelPredictionEnd)
+	registerEvent(b, "channel.hype_train.begin", "1", HypeTrainBeginHandler.OnChannelHypeTrainBegin)
+	registerEvent(b, "channel.hype_train.progress", "1", HypeTrainProgressHandler.OnChannelHypeTrainProgress)
+	registerEvent(b, "channel.hype_train.end", "1", HypeTrainEndHandler.OnChannelHypeTrainEnd)
+	registerEvent(b, "channel.goal.begin", "1", GoalBeginHandler.OnChannelGoalBegin)
+	registerEvent(b, "channel.goal.progress", "1", GoalProgressHandler.OnChannelGoalProgress)
+	registerEvent(b, "channel.goal.end", "1", GoalEndHandler.OnChannelGoalEnd)
+	registerEvent(b, "channel.charity_campaign.start", "1", CharityCampaignStartHandler.OnChannelCharityCampaignStart)
+	registerEvent(b, "channel.charity_campaign.progress", "1", CharityCampaignProgressHandler.OnChannelCharityCampaignProgress)
+	registerEvent(b, "channel.charity_campaign.stop", "1", CharityCampaignStopHandler.OnChannelCharityCampaignStop)
+	registerEvent(b, "channel.charity_campaign.donate", "1", CharityCampaignDonateHandler.OnChannelCharityCampaignDonate)
+	registerEvent(b, "channel.chat.notification", "1", ChatNotificationHandler.OnChannelChatNotification)
+	registerEvent(b, "channel.chat.message_delete", "1", ChatMessageDeleteHandler.OnChannelChatMessageDelete)
+	registerEvent(b, "channel.chat.clear", "1", ChatClearHandler.OnChannelChatClear)
+	registerEvent(b, "channel.chat.clear_user_messages", "1", ChatClearUserMessagesHandler.OnChannelChatClearUserMessages)
+	registerEvent(b, "channel.chat_settings.update", "1", ChatSettingsUpdateHandler.OnChannelChatSettingsUpdate)
+	registerEvent(b, "automod.message.hold", "1", AutomodMessageHoldHandler.OnAutomodMessageHold)
+	registerEvent(b, "automod.message.update", "1", AutomodMessageUpdateHandler.OnAutomodMessageUpdate)
+
+	registerEngineHandler(b, "conduit.shard.disabled", "1", func(ctx context.Context, api *helix.Client, response Response[EventSubConduitShardDisabledEvent, helix.EventSubCondition]) error {
+		b.conduits.handleShardDisabled(ctx, response.Event)
+		return fanOut(b.engine, func(h ConduitShardDisabledHandler) error {
+			return h.OnConduitShardDisabled(ctx, api, response)
+		})
+	})
+}
+
+// registerChatMessage registers channel.chat.message for plugins implementing either
+// ChatMessageHandler or the legacy OnChannelChatMessage without an error result.
+func registerChatMessage(b *Bot) {
+	if !implements[ChatMessageHandler](b.engine) && !implements[legacyChatMessageHandler](b.engine) {
+		return
+	}
+
+	registerEngineHandler(b, "channel.chat.message", "1", func(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelChatMessageEvent, helix.EventSubCondition]) error {
+		return fanOut(b.engine, func(e EventEngine) error {
+			switch h := e.(type) {
+			case ChatMessageHandler:
+				return h.OnChannelChatMessage(ctx, api, response)
+			case legacyChatMessageHandler:
+				h.OnChannelChatMessage(ctx, api, response)
+			}
+			return nil
+		})
+	})
+}
+
+// handlerInterfaces lists the optional interfaces an engine may implement.
+var handlerInterfaces = []reflect.Type{
+	handler[ReadyHandler](),
+	handler[BotStopHandler](),
+	handler[TokenInvalidHandler](),
+	handler[SubscriptionRevokedHandler](),
+	handler[HandlerErrorHandler](),
+	handler[ConduitShardDisabledHandler](),
+	handler[ChatMessageHandler](),
+	handler[ChannelUpdateHandler](),
+	handler[StreamOnlineHandler](),
+	handler[StreamOfflineHandler](),
+	handler[SubscribeHandler](),
+	handler[SubscriptionMessageHandler](),
+	handler[SubscriptionGiftHandler](),
+	handler[SubscriptionEndHandler](),
+	handler[CheerHandler](),
+	handler[ChannelPointsCustomRewardAddHandler](),
+	handler[ChannelPointsCustomRewardUpdateHandler](),
+	handler[ChannelPointsCustomRewardRemoveHandler](),
+	handler[ChannelPointsCustomRewardRedemptionAddHandler](),
+	handler[ChannelPointsCustomRewardRedemptionUpdateHandler](),
+	handler[ChannelPointsAutomaticRewardRedemptionAddHandler](),
+	handler[BanHandler](),
+	handler[UnbanHandler](),
+	handler[ModerateHandler](),
+	handler[WarningSendHandler](),
+	handler[WarningAcknowledgeHandler](),
+	handler[UnbanRequestCreateHandler](),
+	handler[UnbanRequestResolveHandler](),
+	handler[ModeratorAddHandler](),
+	handler[ModeratorRemoveHandler](),
+	handler[VIPAddHandler](),
+	handler[VIPRemoveHandler](),
+	handler[PollBeginHandler](),
+	handler[PollProgressHandler](),
+	handler[PollEndHandler](),
+	handler[PredictionBeginHandler](),
+	handler[PredictionProgressHandler](),
+	handler[PredictionLockHandler](),
+	handler[PredictionEndHandler](),
+	handler[HypeTrainBeginHandler](),
+	handler[HypeTrainProgressHandler](),
+	handler[HypeTrainEndHandler](),
+	handler[GoalBeginHandler](),
+	handler[GoalProgressHandler](),
+	handler[GoalEndHandler](),
+	handler[CharityCampaignStartHandler](),
+	handler[CharityCampaignProgressHandler](),
+	handler[CharityCampaignStopHandler](),
+	handler[CharityCampaignDonateHandler](),
+	handler[ChatNotificationHandler](),
+	handler[ChatMessageDeleteHandler](),
+	handler[ChatClearHandler](),
+	handler[ChatClearUserMessagesHandler](),
+	handler[ChatSettingsUpdateHandler](),
+	handler[AutomodMessageHoldHandler](),
+	handler[AutomodMessageUpdateHandler](),
+}
+
+func handler[H interface{}]() reflect.Type {
+	return reflect.TypeOf((*H)(nil)).Elem()
+}
+
+// checkEngine reports the methods of engine named after a handler interface's method
+// but with a different signature, which would otherwise silently receive no events.
+func checkEngine(engine EventEngine) error {
+	t := reflect.TypeOf(engine)
+
+	var errs []error
+	for _, iface := range handlerInterfaces {
+		if t.Implements(iface) {
+			continue
+		}
+
+		want := iface.Method(0)
+		got, ok := t.MethodByName(want.Name)
+		if !ok {
+			continue
+		}
+		if _, legacy := engine.(legacyChatMessageHandler); legacy && iface == handler[ChatMessageHandler]() {
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("%s has signature %s, want %s to implement %s", want.Name, methodSignature(got.Type), want.Type, iface.Name()))
+	}
+	return errors.Join(errs...)
+}
+
+// methodSignature formats the type of a method value without its receiver.
+func methodSignature(t reflect.Type) string {
+	in := make([]reflect.Type, 0, t.NumIn()-1)
+	for i := 1; i < t.NumIn(); i++ {
+		in = append(in, t.In(i))
+	}
+	out := make([]reflect.Type, 0, t.NumOut())
+	for i := 0; i < t.NumOut(); i++ {
+		out = append(out, t.Out(i))
+	}
+	return reflect.FuncOf(in, out, t.IsVariadic()).String()
+}
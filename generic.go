@@ -6,7 +6,13 @@ type Status string
 const (
 	Webhook   Method = "webhook"
 	Websocket Method = "websocket"
+	Conduit   Method = "conduit"
 
 	Enabled  Status = "enabled"
 	Disabled Status = "disabled"
+
+	AuthorizationRevoked         Status = "authorization_revoked"
+	UserRemoved                  Status = "user_removed"
+	NotificationFailuresExceeded Status = "notification_failures_exceeded"
+	VersionRemoved               Status = "version_removed"
 )
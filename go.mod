@@ -4,6 +4,7 @@ go 1.23.2
 
 require (
 	github.com/go-chi/chi/v5 v5.2.3
+	github.com/gorilla/websocket v1.5.3
 	github.com/nicklaw5/helix/v2 v2.31.1
 	github.com/rs/zerolog v1.34.0
 )
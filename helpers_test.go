@@ -0,0 +1,140 @@
+package twitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Etwodev/twitchgo/pkg/config"
+	"github.com/Etwodev/twitchgo/pkg/log"
+	"github.com/nicklaw5/helix/v2"
+)
+
+// newTestBot creates a bot that keeps tokens in memory, discards its logs and reads
+// secrets from a fixed source, with opts applied on top.
+func newTestBot(t *testing.T, engine EventEngine, opts ...Option) *Bot {
+	t.Helper()
+
+	defaults := []Option{
+		WithConfig(config.Default()),
+		WithLogger(&log.NoOpLogger{}),
+		WithSecretSource(func(name string) string {
+			return "test-" + strings.ToLower(name)
+		}),
+	}
+
+	b, err := NewWithOptions(engine, append(defaults, opts...)...)
+	if err != nil {
+		t.Fatalf("NewWithOptions: %v", err)
+	}
+
+	t.Cleanup(func() {
+		b.cancel()
+		_ = b.dispatcher.drain(context.Background())
+	})
+	return b
+}
+
+// receive waits for a value on ch, failing the test if none arrives in time.
+func receive[T interface{}](t *testing.T, ch <-chan T, what string) T {
+	t.Helper()
+
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+	panic("unreachable")
+}
+
+// fakeRequest is a request received by a fakeHelix.
+type fakeRequest struct {
+	Method string
+	Path   string
+	Query  map[string][]string
+	Body   []byte
+}
+
+// fakeHelix stands in for the Helix API, answering each "METHOD /path" route with
+// its handler and recording every request.
+type fakeHelix struct {
+	*httptest.Server
+	t        *testing.T
+	mu       sync.Mutex
+	routes   map[string]http.HandlerFunc
+	requests []fakeRequest
+}
+
+func newFakeHelix(t *testing.T) *fakeHelix {
+	t.Helper()
+
+	f := &fakeHelix{t: t, routes: make(map[string]http.HandlerFunc)}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+		route := r.Method + " " + r.URL.Path
+
+		f.mu.Lock()
+		f.requests = append(f.requests, fakeRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Body: body})
+		handler, ok := f.routes[route]
+		f.mu.Unlock()
+
+		if !ok {
+			t.Errorf("unexpected request %s", route)
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// handle answers route, such as "GET /eventsub/subscriptions", with handler.
+func (f *fakeHelix) handle(route string, handler http.HandlerFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.routes[route] = handler
+}
+
+// received returns the requests made to route.
+func (f *fakeHelix) received(route string) []fakeRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var requests []fakeRequest
+	for _, r := range f.requests {
+		if r.Method+" "+r.Path == route {
+			requests = append(requests, r)
+		}
+	}
+	return requests
+}
+
+// client returns a helix client calling the fake with an app access token.
+func (f *fakeHelix) client() *helix.Client {
+	client, err := helix.NewClient(&helix.Options{
+		ClientID:       "test-client",
+		AppAccessToken: "test-app-token",
+		APIBaseURL:     f.URL,
+	})
+	if err != nil {
+		f.t.Fatalf("helix.NewClient: %v", err)
+	}
+	return client
+}
+
+// writeJSON writes v as a JSON response with status.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -88,7 +88,20 @@ var FULL_AUTH_SCOPES = []string{
 	"whispers:read",
 }
 
+// HandleLogin redirects to the Twitch authorization page, requesting FULL_AUTH_SCOPES.
+func (b *Bot) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	handleLogin(w, r, b.config.ClientID, b.config.RedirectUri, b.config.EnableTLS, b.now())
+}
+
+// HandleLogin redirects to the Twitch authorization page using the package configuration
+// loaded by config.New.
+//
+// Deprecated: Use Bot.HandleLogin, which uses the bot's own configuration.
 func HandleLogin(w http.ResponseWriter, r *http.Request) {
+	handleLogin(w, r, config.ClientID(), config.RedirectUri(), config.EnableTLS(), time.Now())
+}
+
+func handleLogin(w http.ResponseWriter, r *http.Request, clientID, redirectURI string, secure bool, now time.Time) {
 	state, err := helpers.GenerateState(24)
 	if err != nil {
 		http.Error(w, "internal server error", http.StatusInternalServerError)
@@ -100,16 +113,16 @@ func HandleLogin(w http.ResponseWriter, r *http.Request) {
 		Value:    state,
 		Path:     "/",
 		HttpOnly: true,
-		Secure:   config.EnableTLS(),
+		Secure:   secure,
 		SameSite: http.SameSiteLaxMode,
-		Expires:  time.Now().Add(10 * time.Minute),
+		Expires:  now.Add(10 * time.Minute),
 	}
 	http.SetCookie(w, cookie)
 
 	authURL := fmt.Sprintf(
 		"https://id.twitch.tv/oauth2/authorize?client_id=%s&redirect_uri=%s&response_type=code&scope=%s&state=%s",
-		url.QueryEscape(config.ClientID()),
-		url.QueryEscape(config.RedirectUri()),
+		url.QueryEscape(clientID),
+		url.QueryEscape(redirectURI),
 		url.QueryEscape(strings.Join(FULL_AUTH_SCOPES, " ")),
 		url.QueryEscape(state),
 	)
@@ -4,7 +4,6 @@ import (
 	"net/http"
 	"path"
 
-	c "github.com/Etwodev/twitchgo/pkg/config"
 	"github.com/Etwodev/twitchgo/pkg/middleware"
 	"github.com/go-chi/chi/v5"
 )
@@ -24,7 +23,7 @@ func (b *Bot) handler() *chi.Mux {
 }
 
 func (b *Bot) initMux(m *chi.Mux) {
-	if c.EnableRequestLogging() {
+	if b.config.EnableRequestLogging {
 		middleware := middleware.NewLoggingMiddleware(b.logger)
 
 		b.logger.Debug().
@@ -36,8 +35,8 @@ func (b *Bot) initMux(m *chi.Mux) {
 		m.Use(middleware.Method())
 	}
 
-	if c.EnableCORS() && len(c.AllowedOrigins()) > 0 {
-		middleware := middleware.NewCORSMiddleware(c.AllowedOrigins())
+	if b.config.EnableCORS && len(b.config.AllowedOrigins) > 0 {
+		middleware := middleware.NewCORSMiddleware(b.config.AllowedOrigins)
 
 		b.logger.Debug().
 			Str("Name", middleware.Name()).
@@ -49,7 +48,7 @@ func (b *Bot) initMux(m *chi.Mux) {
 	}
 
 	for _, middleware := range b.middlewares {
-		if middleware.Status() && (middleware.Experimental() == c.Experimental() || !middleware.Experimental()) {
+		if middleware.Status() && (middleware.Experimental() == b.config.Experimental || !middleware.Experimental()) {
 			b.logger.Debug().
 				Str("Name", middleware.Name()).
 				Bool("Experimental", middleware.Experimental()).
@@ -71,7 +70,7 @@ func (b *Bot) initMux(m *chi.Mux) {
 			}
 
 			for _, rt := range rtr.Routes() {
-				if !rt.Status() || (rt.Experimental() != c.Experimental() && rt.Experimental()) {
+				if !rt.Status() || (rt.Experimental() != b.config.Experimental && rt.Experimental()) {
 					continue
 				}
 
@@ -0,0 +1,127 @@
+package twitchgo
+
+import (
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Etwodev/twitchgo/pkg/config"
+	"github.com/Etwodev/twitchgo/pkg/log"
+)
+
+// SecretSource looks up a secret by name, such as "CLIENT_SECRET", "CALLBACK_USER"
+// or "CALLBACK_PASS".
+//
+// Secrets are looked up when needed, such as the callback credentials on each request,
+// except the CLIENT_SECRET the helix clients use to refresh user tokens, which is read
+// when the bot is created. A rotated client secret requires creating a new bot.
+type SecretSource func(name string) string
+
+// Clock tells the bot the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the Clock used by default, reading the system time.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// options holds the values set by Option functions for NewWithOptions.
+type options struct {
+	config    *config.Config
+	logger    log.Logger
+	secrets   SecretSource
+	client    *http.Client
+	transport http.RoundTripper
+	clock     Clock
+	tokens    TokenStore
+}
+
+// Option configures a Bot created with NewWithOptions.
+type Option func(o *options)
+
+// WithConfig sets the configuration of the bot, instead of loading ./twitchgo.config.json.
+//
+// Example:
+//
+//	cfg := config.Default()
+//	cfg.Port = "8080"
+//	bot, err := twitchgo.NewWithOptions(engine, twitchgo.WithConfig(cfg))
+func WithConfig(cfg config.Config) Option {
+	return func(o *options) {
+		o.config = &cfg
+	}
+}
+
+// WithLogger sets the logger of the bot, instead of a zerolog console writer at the configured level.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithSecretSource sets where the bot looks up secrets, instead of the environment.
+//
+// Example:
+//
+//	bot, err := twitchgo.NewWithOptions(engine, twitchgo.WithSecretSource(func(name string) string {
+//	    return vault.Get(name)
+//	}))
+func WithSecretSource(secrets SecretSource) Option {
+	return func(o *options) {
+		o.secrets = secrets
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to call Twitch. Its transport is wrapped
+// to refresh expired user access tokens.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// WithTransport sets the HTTP transport used to call Twitch, instead of http.DefaultTransport.
+// It takes precedence over the transport of a client set with WithHTTPClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+// WithClock sets the clock the bot reads the time from, instead of the system clock.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithTokenStore sets where the bot persists user tokens, instead of the configured tokenFile.
+//
+// Example:
+//
+//	bot, err := twitchgo.NewWithOptions(engine, twitchgo.WithTokenStore(twitchgo.NewMemoryTokenStore()))
+func WithTokenStore(tokens TokenStore) Option {
+	return func(o *options) {
+		o.tokens = tokens
+	}
+}
+
+// secret looks up a secret from the bot's secret source.
+func (b *Bot) secret(name string) string {
+	return b.secrets(name)
+}
+
+// now returns the current time from the bot's clock.
+func (b *Bot) now() time.Time {
+	return b.clock.Now()
+}
+
+// defaultOptions returns the options used for anything not set by an Option.
+func defaultOptions() *options {
+	return &options{
+		secrets: os.Getenv,
+		clock:   systemClock{},
+	}
+}
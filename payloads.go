@@ -0,0 +1,298 @@
+package twitchgo
+
+import "github.com/nicklaw5/helix/v2"
+
+// Event payloads for EventSub subscription types that helix does not model.
+//
+// See: https://dev.twitch.tv/docs/eventsub/eventsub-reference for more information.
+
+// EventSubChannelSubscriptionEndEvent is the data for a channel subscription end notification,
+// it's the same as the channel subscribe notification.
+type EventSubChannelSubscriptionEndEvent = helix.EventSubChannelSubscribeEvent
+
+// EventSubChannelPointsAutomaticRewardRedemptionEvent is the data for a channel points
+// automatic reward redemption notification (v2).
+type EventSubChannelPointsAutomaticRewardRedemptionEvent struct {
+	ID                   string                       `json:"id"`
+	BroadcasterUserID    string                       `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string                       `json:"broadcaster_user_login"`
+	BroadcasterUserName  string                       `json:"broadcaster_user_name"`
+	UserID               string                       `json:"user_id"`
+	UserLogin            string                       `json:"user_login"`
+	UserName             string                       `json:"user_name"`
+	Reward               EventSubAutomaticReward      `json:"reward"`
+	Message              EventSubAutomaticRewardInput `json:"message"`
+	RedeemedAt           helix.Time                   `json:"redeemed_at"`
+}
+
+// EventSubAutomaticReward belongs to an automatic reward redemption and defines the reward redeemed.
+type EventSubAutomaticReward struct {
+	Type          string               `json:"type"`
+	ChannelPoints int                  `json:"channel_points"`
+	Emote         *EventSubRewardEmote `json:"emote"`
+}
+
+// EventSubRewardEmote is the emote unlocked or modified by an automatic reward, if any.
+type EventSubRewardEmote struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// EventSubAutomaticRewardInput is the message sent with an automatic reward redemption.
+type EventSubAutomaticRewardInput struct {
+	Text      string                            `json:"text"`
+	Fragments []EventSubAutomaticRewardFragment `json:"fragments"`
+}
+
+// EventSubAutomaticRewardFragment is a text or emote fragment of an automatic reward message.
+type EventSubAutomaticRewardFragment struct {
+	Type  string               `json:"type"`
+	Text  string               `json:"text"`
+	Emote *EventSubRewardEmote `json:"emote"`
+}
+
+// EventSubChannelModerateEvent is the data for a channel moderate notification (v2).
+//
+// Only the field matching Action is set.
+type EventSubChannelModerateEvent struct {
+	BroadcasterUserID          string                        `json:"broadcaster_user_id"`
+	BroadcasterUserLogin       string                        `json:"broadcaster_user_login"`
+	BroadcasterUserName        string                        `json:"broadcaster_user_name"`
+	SourceBroadcasterUserID    string                        `json:"source_broadcaster_user_id"`
+	SourceBroadcasterUserLogin string                        `json:"source_broadcaster_user_login"`
+	SourceBroadcasterUserName  string                        `json:"source_broadcaster_user_name"`
+	ModeratorUserID            string                        `json:"moderator_user_id"`
+	ModeratorUserLogin         string                        `json:"moderator_user_login"`
+	ModeratorUserName          string                        `json:"moderator_user_name"`
+	Action                     string                        `json:"action"`
+	Followers                  *EventSubModerateFollowers    `json:"followers"`
+	Slow                       *EventSubModerateSlow         `json:"slow"`
+	Vip                        *EventSubModerateUser         `json:"vip"`
+	Unvip                      *EventSubModerateUser         `json:"unvip"`
+	Mod                        *EventSubModerateUser         `json:"mod"`
+	Unmod                      *EventSubModerateUser         `json:"unmod"`
+	Ban                        *EventSubModerateBan          `json:"ban"`
+	Unban                      *EventSubModerateUser         `json:"unban"`
+	Timeout                    *EventSubModerateTimeout      `json:"timeout"`
+	Untimeout                  *EventSubModerateUser         `json:"untimeout"`
+	Raid                       *EventSubModerateRaid         `json:"raid"`
+	Unraid                     *EventSubModerateUser         `json:"unraid"`
+	Delete                     *EventSubModerateDelete       `json:"delete"`
+	AutomodTerms               *EventSubModerateAutomodTerms `json:"automod_terms"`
+	UnbanRequest               *EventSubModerateUnbanRequest `json:"unban_request"`
+	Warn                       *EventSubModerateWarn         `json:"warn"`
+	SharedChatBan              *EventSubModerateBan          `json:"shared_chat_ban"`
+	SharedChatUnban            *EventSubModerateUser         `json:"shared_chat_unban"`
+	SharedChatTimeout          *EventSubModerateTimeout      `json:"shared_chat_timeout"`
+	SharedChatUntimeout        *EventSubModerateUser         `json:"shared_chat_untimeout"`
+	SharedChatDelete           *EventSubModerateDelete       `json:"shared_chat_delete"`
+}
+
+// EventSubModerateUser identifies the user targeted by a moderation action.
+type EventSubModerateUser struct {
+	UserID    string `json:"user_id"`
+	UserLogin string `json:"user_login"`
+	UserName  string `json:"user_name"`
+}
+
+// EventSubModerateFollowers belongs to a followers-only mode action.
+type EventSubModerateFollowers struct {
+	FollowDurationMinutes int `json:"follow_duration_minutes"`
+}
+
+// EventSubModerateSlow belongs to a slow mode action.
+type EventSubModerateSlow struct {
+	WaitTimeSeconds int `json:"wait_time_seconds"`
+}
+
+// EventSubModerateBan belongs to a ban action.
+type EventSubModerateBan struct {
+	UserID    string `json:"user_id"`
+	UserLogin string `json:"user_login"`
+	UserName  string `json:"user_name"`
+	Reason    string `json:"reason"`
+}
+
+// EventSubModerateTimeout belongs to a timeout action.
+type EventSubModerateTimeout struct {
+	UserID    string     `json:"user_id"`
+	UserLogin string     `json:"user_login"`
+	UserName  string     `json:"user_name"`
+	Reason    string     `json:"reason"`
+	ExpiresAt helix.Time `json:"expires_at"`
+}
+
+// EventSubModerateRaid belongs to a raid action.
+type EventSubModerateRaid struct {
+	UserID      string `json:"user_id"`
+	UserLogin   string `json:"user_login"`
+	UserName    string `json:"user_name"`
+	ViewerCount int    `json:"viewer_count"`
+}
+
+// EventSubModerateDelete belongs to a message delete action.
+type EventSubModerateDelete struct {
+	UserID      string `json:"user_id"`
+	UserLogin   string `json:"user_login"`
+	UserName    string `json:"user_name"`
+	MessageID   string `json:"message_id"`
+	MessageBody string `json:"message_body"`
+}
+
+// EventSubModerateAutomodTerms belongs to an add or remove blocked/permitted terms action.
+type EventSubModerateAutomodTerms struct {
+	Action      string   `json:"action"`
+	List        string   `json:"list"`
+	Terms       []string `json:"terms"`
+	FromAutomod bool     `json:"from_automod"`
+}
+
+// EventSubModerateUnbanRequest belongs to an approve or deny unban request action.
+type EventSubModerateUnbanRequest struct {
+	IsApproved       bool   `json:"is_approved"`
+	UserID           string `json:"user_id"`
+	UserLogin        string `json:"user_login"`
+	UserName         string `json:"user_name"`
+	ModeratorMessage string `json:"moderator_message"`
+}
+
+// EventSubModerateWarn belongs to a warn action.
+type EventSubModerateWarn struct {
+	UserID         string   `json:"user_id"`
+	UserLogin      string   `json:"user_login"`
+	UserName       string   `json:"user_name"`
+	Reason         string   `json:"reason"`
+	ChatRulesCited []string `json:"chat_rules_cited"`
+}
+
+// EventSubChannelWarningSendEvent is the data for a channel warning send notification.
+type EventSubChannelWarningSendEvent struct {
+	BroadcasterUserID    string   `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string   `json:"broadcaster_user_login"`
+	BroadcasterUserName  string   `json:"broadcaster_user_name"`
+	ModeratorUserID      string   `json:"moderator_user_id"`
+	ModeratorUserLogin   string   `json:"moderator_user_login"`
+	ModeratorUserName    string   `json:"moderator_user_name"`
+	UserID               string   `json:"user_id"`
+	UserLogin            string   `json:"user_login"`
+	UserName             string   `json:"user_name"`
+	Reason               string   `json:"reason"`
+	ChatRulesCited       []string `json:"chat_rules_cited"`
+}
+
+// EventSubChannelWarningAcknowledgeEvent is the data for a channel warning acknowledge notification.
+type EventSubChannelWarningAcknowledgeEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	UserID               string `json:"user_id"`
+	UserLogin            string `json:"user_login"`
+	UserName             string `json:"user_name"`
+}
+
+// EventSubChannelUnbanRequestCreateEvent is the data for a channel unban request create notification.
+type EventSubChannelUnbanRequestCreateEvent struct {
+	ID                   string     `json:"id"`
+	BroadcasterUserID    string     `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string     `json:"broadcaster_user_login"`
+	BroadcasterUserName  string     `json:"broadcaster_user_name"`
+	UserID               string     `json:"user_id"`
+	UserLogin            string     `json:"user_login"`
+	UserName             string     `json:"user_name"`
+	Text                 string     `json:"text"`
+	CreatedAt            helix.Time `json:"created_at"`
+}
+
+// EventSubChannelUnbanRequestResolveEvent is the data for a channel unban request resolve notification.
+//
+// Status is one of "approved", "canceled" or "denied".
+type EventSubChannelUnbanRequestResolveEvent struct {
+	ID                   string `json:"id"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	ModeratorUserID      string `json:"moderator_user_id"`
+	ModeratorUserLogin   string `json:"moderator_user_login"`
+	ModeratorUserName    string `json:"moderator_user_name"`
+	UserID               string `json:"user_id"`
+	UserLogin            string `json:"user_login"`
+	UserName             string `json:"user_name"`
+	ResolutionText       string `json:"resolution_text"`
+	Status               string `json:"status"`
+}
+
+// EventSubChannelVIPEvent is the data for a channel VIP add or remove notification.
+type EventSubChannelVIPEvent struct {
+	UserID               string `json:"user_id"`
+	UserLogin            string `json:"user_login"`
+	UserName             string `json:"user_name"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+}
+
+// EventSubChannelChatSettingsUpdateEvent is the data for a channel chat settings update notification.
+//
+// FollowerModeDurationMinutes and SlowModeWaitTimeSeconds are nil when the mode is disabled.
+type EventSubChannelChatSettingsUpdateEvent struct {
+	BroadcasterUserID           string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin        string `json:"broadcaster_user_login"`
+	BroadcasterUserName         string `json:"broadcaster_user_name"`
+	EmoteMode                   bool   `json:"emote_mode"`
+	FollowerMode                bool   `json:"follower_mode"`
+	FollowerModeDurationMinutes *int   `json:"follower_mode_duration_minutes"`
+	SlowMode                    bool   `json:"slow_mode"`
+	SlowModeWaitTimeSeconds     *int   `json:"slow_mode_wait_time_seconds"`
+	SubscriberMode              bool   `json:"subscriber_mode"`
+	UniqueChatMode              bool   `json:"unique_chat_mode"`
+}
+
+// EventSubAutomodMessageHoldEvent is the data for an automod message hold notification.
+type EventSubAutomodMessageHoldEvent struct {
+	BroadcasterUserID    string                 `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string                 `json:"broadcaster_user_login"`
+	BroadcasterUserName  string                 `json:"broadcaster_user_name"`
+	UserID               string                 `json:"user_id"`
+	UserLogin            string                 `json:"user_login"`
+	UserName             string                 `json:"user_name"`
+	MessageID            string                 `json:"message_id"`
+	Message              EventSubAutomodMessage `json:"message"`
+	Category             string                 `json:"category"`
+	Level                int                    `json:"level"`
+	HeldAt               helix.Time             `json:"held_at"`
+}
+
+// EventSubAutomodMessageUpdateEvent is the data for an automod message update notification.
+//
+// Status is one of "Approved", "Denied" or "Expired".
+type EventSubAutomodMessageUpdateEvent struct {
+	BroadcasterUserID    string                 `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string                 `json:"broadcaster_user_login"`
+	BroadcasterUserName  string                 `json:"broadcaster_user_name"`
+	UserID               string                 `json:"user_id"`
+	UserLogin            string                 `json:"user_login"`
+	UserName             string                 `json:"user_name"`
+	ModeratorUserID      string                 `json:"moderator_user_id"`
+	ModeratorUserLogin   string                 `json:"moderator_user_login"`
+	ModeratorUserName    string                 `json:"moderator_user_name"`
+	MessageID            string                 `json:"message_id"`
+	Message              EventSubAutomodMessage `json:"message"`
+	Category             string                 `json:"category"`
+	Level                int                    `json:"level"`
+	Status               string                 `json:"status"`
+	HeldAt               helix.Time             `json:"held_at"`
+}
+
+// EventSubAutomodMessage is the message held by automod.
+type EventSubAutomodMessage struct {
+	Text      string                              `json:"text"`
+	Fragments []helix.EventSubChatMessageFragment `json:"fragments"`
+}
+
+// EventSubConduitShardDisabledEvent is the data for a conduit shard disabled notification.
+type EventSubConduitShardDisabledEvent struct {
+	ConduitID string           `json:"conduit_id"`
+	ShardID   string           `json:"shard_id"`
+	Status    string           `json:"status"`
+	Transport ConduitTransport `json:"transport"`
+}
@@ -13,6 +13,9 @@ var c *Config
 // Load reads the configuration file from disk, parses the JSON content,
 // and loads it into the package-level Config variable `c`.
 //
+// Fields missing from the file keep their Default values, so files written
+// by older versions still load with sensible settings.
+//
 // If the config file does not exist, it will attempt to create one with default values.
 //
 // Returns an error if reading or unmarshalling the file fails.
@@ -36,10 +39,13 @@ func Load() error {
 		return fmt.Errorf("Load: failed reading json: %w", err)
 	}
 
-	err = json.Unmarshal(file, &c)
+	loaded := Default()
+	err = json.Unmarshal(file, &loaded)
 	if err != nil {
 		return fmt.Errorf("Load: failed unmarshalling json: %w", err)
 	}
+
+	c = &loaded
 	return nil
 }
 
@@ -54,24 +60,7 @@ func Load() error {
 //
 //	err := config.Create(&config.Config{Port: "8080"})
 func Create(override *Config) error {
-	defaultConfig := Config{
-		Port:                 "7000",
-		Address:              "0.0.0.0",
-		Experimental:         false,
-		ReadTimeout:          15,
-		WriteTimeout:         15,
-		IdleTimeout:          60,
-		LogLevel:             "info",
-		MaxHeaderBytes:       1048576,
-		EnableTLS:            false,
-		TLSCertFile:          "",
-		TLSKeyFile:           "",
-		ShutdownTimeout:      15,
-		Scopes:               []string{"channel:moderate"},
-		EnableRequestLogging: false,
-		RedirectUri:          "https://example.com",
-		ClientID:             "unknown",
-	}
+	defaultConfig := Default()
 
 	if override != nil {
 		defaultConfig = *override
@@ -110,3 +99,55 @@ func New() error {
 	}
 	return nil
 }
+
+// Default returns a Config holding the default values written by Create.
+//
+// Example usage:
+//
+//	cfg := config.Default()
+//	cfg.Port = "8080"
+func Default() Config {
+	return Config{
+		Port:                 "7000",
+		Address:              "0.0.0.0",
+		Experimental:         false,
+		ReadTimeout:          15,
+		WriteTimeout:         15,
+		IdleTimeout:          60,
+		LogLevel:             "info",
+		MaxHeaderBytes:       1048576,
+		EnableTLS:            false,
+		TLSCertFile:          "",
+		TLSKeyFile:           "",
+		ShutdownTimeout:      15,
+		Scopes:               []string{"channel:moderate"},
+		EnableRequestLogging: false,
+		RedirectUri:          "https://example.com",
+		ClientID:             "unknown",
+		Transport:            "webhook",
+		WebsocketURL:         "wss://eventsub.wss.twitch.tv/ws",
+		AutoResubscribe:      false,
+		CallbackURL:          "",
+		SyncInterval:         300,
+		ConduitID:            "",
+		ConduitShard:         "0",
+		EventTimeout:         30,
+		EventWorkers:         16,
+		EventQueueSize:       256,
+		EventOverflow:        "block",
+		TokenFile:            "",
+		TokenKeyFile:         "",
+		BotUserID:            "",
+	}
+}
+
+// Get returns a copy of the loaded package configuration.
+//
+// It panics if the configuration has not been loaded with New or Load.
+//
+// Example usage:
+//
+//	cfg := config.Get()
+func Get() Config {
+	return *c
+}
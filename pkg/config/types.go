@@ -21,6 +21,20 @@ type Config struct {
 	Scopes               []string `json:"scopes"`               // the scopes to use for the client
 	RedirectUri          string   `json:"redirectUri"`          // the url to redirect to from OAuth
 	ClientID             string   `json:"clientId"`             // the client id for the bot
+	Transport            string   `json:"transport"`            // the EventSub transport to use, "webhook" or "websocket"
+	WebsocketURL         string   `json:"websocketUrl"`         // the EventSub websocket url, if the websocket transport is in use
+	AutoResubscribe      bool     `json:"autoResubscribe"`      // whether revoked subscriptions should be recreated when recoverable
+	CallbackURL          string   `json:"callbackUrl"`          // the public url of the webhook callback endpoint
+	SyncInterval         int      `json:"syncInterval"`         // seconds between subscription reconciliations
+	ConduitID            string   `json:"conduitId"`            // the EventSub conduit to assign a shard of to this bot, if any
+	ConduitShard         string   `json:"conduitShard"`         // the conduit shard id to assign to this bot
+	EventTimeout         int      `json:"eventTimeout"`         // seconds an event handler may run before its context is cancelled, 0 for no limit
+	EventWorkers         int      `json:"eventWorkers"`         // number of workers running event handlers
+	EventQueueSize       int      `json:"eventQueueSize"`       // number of events each worker may have waiting
+	EventOverflow        string   `json:"eventOverflow"`        // what to do when a worker queue is full, "block" or "drop"
+	TokenFile            string   `json:"tokenFile"`            // the file user tokens are persisted in, or empty to keep them in memory
+	TokenKeyFile         string   `json:"tokenKeyFile"`         // the file holding the token file encryption keys, instead of TOKEN_KEYS
+	BotUserID            string   `json:"botUserId"`            // the user the bot's own helix client acts as, or empty for the first to log in
 }
 
 // Port returns the configured server port.
@@ -76,3 +90,45 @@ func RedirectUri() string { return c.RedirectUri }
 
 // ClientID returns the  client id for the bot
 func ClientID() string { return c.ClientID }
+
+// Transport returns the EventSub transport to use
+func Transport() string { return c.Transport }
+
+// WebsocketURL returns the EventSub websocket url
+func WebsocketURL() string { return c.WebsocketURL }
+
+// AutoResubscribe indicates if revoked subscriptions should be recreated
+func AutoResubscribe() bool { return c.AutoResubscribe }
+
+// CallbackURL returns the public url of the webhook callback endpoint
+func CallbackURL() string { return c.CallbackURL }
+
+// SyncInterval returns the seconds between subscription reconciliations
+func SyncInterval() int { return c.SyncInterval }
+
+// ConduitID returns the EventSub conduit to assign a shard of to this bot
+func ConduitID() string { return c.ConduitID }
+
+// ConduitShard returns the conduit shard id to assign to this bot
+func ConduitShard() string { return c.ConduitShard }
+
+// EventTimeout returns the seconds an event handler may run before its context is cancelled
+func EventTimeout() int { return c.EventTimeout }
+
+// EventWorkers returns the number of workers running event handlers
+func EventWorkers() int { return c.EventWorkers }
+
+// EventQueueSize returns the number of events each worker may have waiting
+func EventQueueSize() int { return c.EventQueueSize }
+
+// EventOverflow returns what to do when a worker queue is full
+func EventOverflow() string { return c.EventOverflow }
+
+// TokenFile returns the file user tokens are persisted in
+func TokenFile() string { return c.TokenFile }
+
+// TokenKeyFile returns the file holding the token file encryption keys
+func TokenKeyFile() string { return c.TokenKeyFile }
+
+// BotUserID returns the user the bot's own helix client acts as
+func BotUserID() string { return c.BotUserID }
@@ -0,0 +1,279 @@
+package twitchgo
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"sort"
+	"sync"
+
+	"github.com/nicklaw5/helix/v2"
+)
+
+// ErrStopPropagation may be returned by an event callback to stop the event
+// from reaching plugins of lower priority. It is not reported as a failure.
+var ErrStopPropagation = errors.New("stop propagation")
+
+// Plugin is an EventEngine registered alongside others, with metadata controlling
+// whether and in what order it receives events.
+type Plugin interface {
+	// Engine returns the engine receiving the plugin's events and lifecycle hooks.
+	Engine() EventEngine
+
+	// Name returns the identifier of the plugin.
+	Name() string
+
+	// Priority returns the order in which the plugin receives events, highest first.
+	Priority() int
+
+	// Status returns whether the plugin is enabled.
+	Status() bool
+
+	// Experimental returns whether the plugin is experimental, and so only
+	// enabled when experimental features are.
+	Experimental() bool
+}
+
+// plugin implements the Plugin interface.
+type plugin struct {
+	engine       EventEngine
+	name         string
+	priority     int
+	status       bool
+	experimental bool
+}
+
+// Engine returns the engine receiving the plugin's events and lifecycle hooks.
+func (p plugin) Engine() EventEngine {
+	return p.engine
+}
+
+// Name returns the identifier of the plugin.
+func (p plugin) Name() string {
+	return p.name
+}
+
+// Priority returns the order in which the plugin receives events, highest first.
+func (p plugin) Priority() int {
+	return p.priority
+}
+
+// Status returns whether the plugin is enabled.
+func (p plugin) Status() bool {
+	return p.status
+}
+
+// Experimental returns whether the plugin is experimental.
+func (p plugin) Experimental() bool {
+	return p.experimental
+}
+
+// NewPlugin constructs a Plugin from an engine, name, priority, enabled status and experimental flag.
+//
+// Example:
+//
+//	moderation := twitchgo.NewPlugin(&ModerationEngine{}, "Moderation", 100, true, false)
+//	alerts := twitchgo.NewPlugin(&AlertsEngine{}, "Alerts", 0, true, false)
+func NewPlugin(engine EventEngine, name string, priority int, status, experimental bool) Plugin {
+	return plugin{
+		engine:       engine,
+		name:         name,
+		priority:     priority,
+		status:       status,
+		experimental: experimental,
+	}
+}
+
+// LoadPlugin adds one or more plugins to the bot.
+//
+// Plugins receive every event they implement a handler interface for, in order of priority,
+// along with the lifecycle hooks, except events of types with a handler registered by
+// RegisterHandler. Plugins should be loaded before Start.
+//
+// Example:
+//
+//	bot.LoadPlugin([]twitchgo.Plugin{moderation, alerts})
+func (b *Bot) LoadPlugin(plugins []Plugin) {
+	for _, p := range plugins {
+		b.logger.Debug().
+			Str("Name", p.Name()).
+			Int("Priority", p.Priority()).
+			Bool("Experimental", p.Experimental()).
+			Bool("Status", p.Status()).
+			Msg("Loading plugin")
+
+		if err := checkEngine(p.Engine()); err != nil {
+			b.logger.Error().
+				Str("Name", p.Name()).
+				Err(err).
+				Msg("Plugin has handlers that will receive no events")
+		}
+	}
+
+	b.engine.add(plugins...)
+	registerEngine(b)
+}
+
+// engineSet fans lifecycle hooks and events out to the enabled plugins in order of priority.
+type engineSet struct {
+	mu           sync.RWMutex
+	plugins      []Plugin
+	experimental bool
+}
+
+func newEngineSet(experimental bool) *engineSet {
+	return &engineSet{experimental: experimental}
+}
+
+func (s *engineSet) add(plugins ...Plugin) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.plugins = append(s.plugins, plugins...)
+	sort.SliceStable(s.plugins, func(i, j int) bool {
+		return s.plugins[i].Priority() > s.plugins[j].Priority()
+	})
+}
+
+// engines returns the engines of enabled plugins, highest priority first.
+func (s *engineSet) engines() []EventEngine {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	engines := make([]EventEngine, 0, len(s.plugins))
+	for _, p := range s.plugins {
+		if p.Status() && (p.Experimental() == s.experimental || !p.Experimental()) {
+			engines = append(engines, p.Engine())
+		}
+	}
+	return engines
+}
+
+// implements reports whether any loaded plugin implements H, enabled or not.
+func implements[H interface{}](s *engineSet) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, p := range s.plugins {
+		if _, ok := p.Engine().(H); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// OnBotStart calls OnBotStart on every enabled plugin.
+func (s *engineSet) OnBotStart(ctx context.Context, api *helix.Client) {
+	for _, e := range s.engines() {
+		e.OnBotStart(ctx, api)
+	}
+}
+
+// OnClientLogin calls OnClientLogin on every enabled plugin.
+func (s *engineSet) OnClientLogin(ctx context.Context, api *helix.Client) {
+	for _, e := range s.engines() {
+		e.OnClientLogin(ctx, api)
+	}
+}
+
+// OnClientRefresh calls OnClientRefresh on every enabled plugin.
+func (s *engineSet) OnClientRefresh(ctx context.Context, api *helix.Client) {
+	for _, e := range s.engines() {
+		e.OnClientRefresh(ctx, api)
+	}
+}
+
+// OnReady calls OnReady on every enabled plugin implementing ReadyHandler.
+func (s *engineSet) OnReady(ctx context.Context, api *helix.Client) {
+	for _, e := range s.engines() {
+		if h, ok := e.(ReadyHandler); ok {
+			h.OnReady(ctx, api)
+		}
+	}
+}
+
+// OnBotStop calls OnBotStop on every enabled plugin implementing BotStopHandler.
+func (s *engineSet) OnBotStop(ctx context.Context, api *helix.Client) {
+	for _, e := range s.engines() {
+		if h, ok := e.(BotStopHandler); ok {
+			h.OnBotStop(ctx, api)
+		}
+	}
+}
+
+// OnTokenInvalid calls OnTokenInvalid on every enabled plugin implementing TokenInvalidHandler.
+func (s *engineSet) OnTokenInvalid(ctx context.Context, userID, reason string) {
+	for _, e := range s.engines() {
+		if h, ok := e.(TokenInvalidHandler); ok {
+			h.OnTokenInvalid(ctx, userID, reason)
+		}
+	}
+}
+
+// OnHandlerError calls OnHandlerError on every enabled plugin implementing HandlerErrorHandler.
+func (s *engineSet) OnHandlerError(ctx context.Context, event Metadata, err error) {
+	for _, e := range s.engines() {
+		if h, ok := e.(HandlerErrorHandler); ok {
+			h.OnHandlerError(ctx, event, err)
+		}
+	}
+}
+
+// fanOut calls fn on every enabled plugin implementing H, highest priority first,
+// until one returns ErrStopPropagation.
+//
+// A failing or panicking plugin does not prevent the others from receiving the event;
+// their errors are joined.
+func fanOut[H interface{}](s *engineSet, fn func(h H) error) error {
+	var errs []error
+	for _, e := range s.engines() {
+		h, ok := e.(H)
+		if !ok {
+			continue
+		}
+
+		err := call(func() error { return fn(h) })
+		if errors.Is(err, ErrStopPropagation) {
+			break
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// call runs fn, turning a panic into a *PanicError.
+func call(fn func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
+// registerEvent registers a handler that passes a subscription type to every plugin
+// implementing H through method, if any plugin implements it.
+//
+// Example:
+//
+//	registerEvent(b, "stream.online", "1", StreamOnlineHandler.OnStreamOnline)
+func registerEvent[H interface{}, T interface{}, U interface{}](b *Bot, name, version string, method func(H, context.Context, *helix.Client, Response[T, U]) error) {
+	if !implements[H](b.engine) {
+		return
+	}
+
+	registerEngineHandler(b, name, version, func(ctx context.Context, api *helix.Client, response Response[T, U]) error {
+		return fanOut(b.engine, func(h H) error {
+			return method(h, ctx, api, response)
+		})
+	})
+}
+
+// registerEngineHandler registers fn for a subscription type on behalf of the bot's plugins,
+// leaving any handler registered with RegisterHandler in place.
+func registerEngineHandler[T interface{}, U interface{}](b *Bot, name, version string, fn HandlerFunc[T, U]) {
+	key := subscriptionKey(name, version)
+	b.registry.setEngine(key, newRegistration(b, key, fn))
+}
@@ -0,0 +1,129 @@
+package twitchgo
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Etwodev/twitchgo/pkg/config"
+	"github.com/Etwodev/twitchgo/pkg/log"
+	"github.com/nicklaw5/helix/v2"
+)
+
+type streamOnlineEngine struct {
+	BaseEngine
+	online chan string
+}
+
+func (e *streamOnlineEngine) OnStreamOnline(ctx context.Context, api *helix.Client, response Response[helix.EventSubStreamOnlineEvent, helix.EventSubCondition]) error {
+	e.online <- response.Event.BroadcasterUserID
+	return nil
+}
+
+func TestLoadPluginKeepsRegisteredHandlers(t *testing.T) {
+	b := newTestBot(t, nil)
+
+	registered := make(chan string, 1)
+	RegisterHandler(b, "stream.online", "1", func(ctx context.Context, api *helix.Client, r Response[helix.EventSubStreamOnlineEvent, helix.EventSubCondition]) error {
+		registered <- r.Event.BroadcasterUserID
+		return nil
+	})
+
+	plugin := &streamOnlineEngine{online: make(chan string, 1)}
+	b.LoadPlugin([]Plugin{NewPlugin(plugin, "Alerts", 0, true, false)})
+
+	body := `{"subscription":{"id":"sub-1","type":"stream.online","version":"1","condition":{"broadcaster_user_id":"1234"}},` +
+		`"event":{"broadcaster_user_id":"1234","broadcaster_user_login":"streamer"}}`
+	if w := postWebhook(t, b, "message-1", "notification", body); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	receive(t, registered, "registered handler")
+	if err := b.dispatcher.drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	select {
+	case id := <-plugin.online:
+		t.Errorf("plugin received stream.online for %s, want the registered handler only", id)
+	default:
+	}
+}
+
+func TestLoadPluginHandlesUnregisteredTypes(t *testing.T) {
+	b := newTestBot(t, nil)
+
+	plugin := &streamOnlineEngine{online: make(chan string, 1)}
+	b.LoadPlugin([]Plugin{NewPlugin(plugin, "Alerts", 0, true, false)})
+
+	body := `{"subscription":{"id":"sub-1","type":"stream.online","version":"1","condition":{"broadcaster_user_id":"1234"}},` +
+		`"event":{"broadcaster_user_id":"1234","broadcaster_user_login":"streamer"}}`
+	if w := postWebhook(t, b, "message-1", "notification", body); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if id := receive(t, plugin.online, "plugin handler"); id != "1234" {
+		t.Errorf("broadcaster = %q, want 1234", id)
+	}
+
+	// a handler registered after the plugin replaces it
+	registered := make(chan string, 1)
+	RegisterHandler(b, "stream.online", "1", func(ctx context.Context, api *helix.Client, r Response[helix.EventSubStreamOnlineEvent, helix.EventSubCondition]) error {
+		registered <- r.Event.BroadcasterUserID
+		return nil
+	})
+	if w := postWebhook(t, b, "message-2", "notification", body); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	receive(t, registered, "registered handler")
+}
+
+type legacyChatEngine struct {
+	BaseEngine
+	messages chan string
+}
+
+func (e *legacyChatEngine) OnChannelChatMessage(ctx context.Context, api *helix.Client, response Response[helix.EventSubChannelChatMessageEvent, helix.EventSubCondition]) {
+	e.messages <- response.Event.Message.Text
+}
+
+type mismatchedEngine struct {
+	BaseEngine
+}
+
+func (e *mismatchedEngine) OnStreamOnline(ctx context.Context, api *helix.Client, response Response[helix.EventSubStreamOnlineEvent, helix.EventSubCondition]) {
+}
+
+func TestLegacyChatMessageHandlerReceivesMessages(t *testing.T) {
+	engine := &legacyChatEngine{messages: make(chan string, 1)}
+	b := newTestBot(t, engine)
+
+	body := `{"subscription":{"id":"sub-1","type":"channel.chat.message","version":"1","condition":{"broadcaster_user_id":"1234"}},` +
+		`"event":{"broadcaster_user_id":"1234","message_id":"m-1","message":{"text":"hello"}}}`
+	if w := postWebhook(t, b, "message-1", "notification", body); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if text := receive(t, engine.messages, "legacy chat message"); text != "hello" {
+		t.Errorf("message = %q, want hello", text)
+	}
+}
+
+func TestCheckEngineReportsMismatchedHandlers(t *testing.T) {
+	if err := checkEngine(&legacyChatEngine{}); err != nil {
+		t.Errorf("checkEngine(legacy chat engine): %v, want nil", err)
+	}
+	if err := checkEngine(&streamOnlineEngine{}); err != nil {
+		t.Errorf("checkEngine(stream online engine): %v, want nil", err)
+	}
+
+	err := checkEngine(&mismatchedEngine{})
+	if err == nil || !strings.Contains(err.Error(), "OnStreamOnline") || !strings.Contains(err.Error(), "StreamOnlineHandler") {
+		t.Fatalf("checkEngine: %v, want OnStreamOnline reported", err)
+	}
+
+	_, err = NewWithOptions(&mismatchedEngine{}, WithConfig(config.Default()), WithLogger(&log.NoOpLogger{}))
+	if err == nil || !strings.Contains(err.Error(), "OnStreamOnline") {
+		t.Errorf("NewWithOptions: %v, want the mismatched handler reported", err)
+	}
+}
@@ -0,0 +1,208 @@
+package twitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nicklaw5/helix/v2"
+)
+
+// HandlerFunc handles a decoded EventSub notification of a single subscription type.
+//
+// A returned error is logged and reported to the engine's OnHandlerError, if implemented.
+type HandlerFunc[T interface{}, U interface{}] func(ctx context.Context, api *helix.Client, response Response[T, U]) error
+
+// FallbackHandler handles notifications for subscription types without a registered handler.
+//
+// The subscription condition and event are left undecoded.
+type FallbackHandler func(ctx context.Context, api *helix.Client, subscription Subscription[json.RawMessage], event json.RawMessage) error
+
+// Event is a notification passing through the event middleware chain.
+type Event struct {
+	Metadata
+	Subscription Subscription[json.RawMessage] // the subscription, with its condition undecoded
+	Payload      json.RawMessage               // the undecoded event
+	Data         interface{}                   // the decoded Response[T, U] of a registered handler, nil for the fallback
+}
+
+// EventHandler handles a notification, returning an error to report it as failed.
+type EventHandler func(ctx context.Context, event *Event) error
+
+// EventMiddleware wraps an EventHandler with cross-cutting behaviour, such as filtering,
+// rate limiting or timing. A middleware that does not call next drops the event.
+type EventMiddleware func(next EventHandler) EventHandler
+
+// EventData returns the decoded response carried by event, if it is a Response[T, U].
+//
+// Example:
+//
+//	if r, ok := twitchgo.EventData[helix.EventSubChannelChatMessageEvent, helix.EventSubCondition](event); ok {
+//	    // inspect r.Event.ChatterUserID
+//	}
+func EventData[T interface{}, U interface{}](event *Event) (Response[T, U], bool) {
+	response, ok := event.Data.(Response[T, U])
+	return response, ok
+}
+
+// registration holds how a subscription type is decoded and handled.
+type registration struct {
+	decode func(body []byte) (interface{}, error)
+	handle EventHandler
+	engine bool // registered for the bot's plugins, and so replaced by RegisterHandler
+}
+
+// registry maps subscription types to their handlers and event middleware.
+type registry struct {
+	mu          sync.RWMutex
+	handlers    map[SubscriptionType]registration
+	fallback    FallbackHandler
+	middlewares []EventMiddleware
+	typed       map[SubscriptionType][]EventMiddleware
+}
+
+func newRegistry() *registry {
+	return &registry{
+		handlers: make(map[SubscriptionType]registration),
+		typed:    make(map[SubscriptionType][]EventMiddleware),
+	}
+}
+
+func (r *registry) set(key SubscriptionType, reg registration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[key] = reg
+}
+
+// setEngine sets the plugin registration of key, unless RegisterHandler has registered one.
+func (r *registry) setEngine(key SubscriptionType, reg registration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.handlers[key]; ok && !existing.engine {
+		return
+	}
+	reg.engine = true
+	r.handlers[key] = reg
+}
+
+func (r *registry) get(key SubscriptionType) (registration, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	reg, ok := r.handlers[key]
+	return reg, ok
+}
+
+func (r *registry) use(key SubscriptionType, middlewares []EventMiddleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if key == "" {
+		r.middlewares = append(r.middlewares, middlewares...)
+		return
+	}
+	r.typed[key] = append(r.typed[key], middlewares...)
+}
+
+// chain wraps handler with the global middleware followed by those of key,
+// so that the first global middleware runs first.
+func (r *registry) chain(key SubscriptionType, handler EventHandler) EventHandler {
+	r.mu.RLock()
+	middlewares := append(append([]EventMiddleware{}, r.middlewares...), r.typed[key]...)
+	r.mu.RUnlock()
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+func (r *registry) setFallback(fn FallbackHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = fn
+}
+
+func (r *registry) getFallback() FallbackHandler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.fallback
+}
+
+// subscriptionKey builds the registry key for a subscription type and version,
+// e.g. "channel.chat.message" and "1" become "channel.chat.message.v1".
+func subscriptionKey(name, version string) SubscriptionType {
+	return SubscriptionType(strings.ToLower(name + ".v" + version))
+}
+
+// RegisterHandler registers fn to handle notifications of the given subscription type and version.
+//
+// Registering a handler for a type that already has one replaces it. It takes precedence
+// over plugins handling the type, whether they are loaded before or after.
+//
+// Example:
+//
+//	twitchgo.RegisterHandler(bot, "channel.follow", "2",
+//	    func(ctx context.Context, api *helix.Client, r twitchgo.Response[helix.EventSubChannelFollowEvent, helix.EventSubCondition]) error {
+//	        // handle follow
+//	        return nil
+//	    })
+func RegisterHandler[T interface{}, U interface{}](b *Bot, name, version string, fn HandlerFunc[T, U]) {
+	key := subscriptionKey(name, version)
+	b.registry.set(key, newRegistration(b, key, fn))
+}
+
+// newRegistration builds the registration decoding notifications of key into a Response[T, U] for fn.
+func newRegistration[T interface{}, U interface{}](b *Bot, key SubscriptionType, fn HandlerFunc[T, U]) registration {
+	return registration{
+		decode: func(body []byte) (interface{}, error) {
+			var response Response[T, U]
+			if err := json.Unmarshal(body, &response); err != nil {
+				return nil, err
+			}
+			return response, nil
+		},
+		handle: func(ctx context.Context, event *Event) error {
+			response, ok := EventData[T, U](event)
+			if !ok {
+				return fmt.Errorf("RegisterHandler: unexpected event data %T for %s", event.Data, key)
+			}
+			return fn(ctx, b.helix, response)
+		},
+	}
+}
+
+// SetFallbackHandler sets the handler used for subscription types without a registered handler.
+//
+// Without a fallback, such notifications are logged and acknowledged.
+//
+// Example:
+//
+//	bot.SetFallbackHandler(func(ctx context.Context, api *helix.Client, s twitchgo.Subscription[json.RawMessage], event json.RawMessage) error {
+//	    // inspect s.Type and decode event
+//	    return nil
+//	})
+func (b *Bot) SetFallbackHandler(fn FallbackHandler) {
+	b.registry.setFallback(fn)
+}
+
+// LoadEventMiddleware appends one or more middleware to the event chain of every subscription type.
+//
+// Event middleware runs on the dispatch worker before the handler, in the order it was loaded,
+// and ahead of any middleware loaded for a single subscription type.
+//
+// Example:
+//
+//	bot.LoadEventMiddleware([]twitchgo.EventMiddleware{timingMw, tracingMw})
+func (b *Bot) LoadEventMiddleware(middlewares []EventMiddleware) {
+	b.registry.use("", middlewares)
+}
+
+// LoadSubscriptionMiddleware appends one or more middleware to the event chain of a single subscription type.
+//
+// Example:
+//
+//	bot.LoadSubscriptionMiddleware(twitchgo.ChannelChatMessage, []twitchgo.EventMiddleware{ignoreSelfMw})
+func (b *Bot) LoadSubscriptionMiddleware(typ SubscriptionType, middlewares []EventMiddleware) {
+	b.registry.use(typ, middlewares)
+}
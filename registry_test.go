@@ -0,0 +1,100 @@
+package twitchgo
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nicklaw5/helix/v2"
+)
+
+// streamOnlineBody returns a stream.online notification for the broadcaster login.
+func streamOnlineBody(login string) string {
+	return `{"subscription":{"id":"sub-1","type":"stream.online","version":"1","condition":{"broadcaster_user_id":"1234"}},` +
+		`"event":{"broadcaster_user_id":"1234","broadcaster_user_login":"` + login + `"}}`
+}
+
+func TestEventMiddlewareOrder(t *testing.T) {
+	b := newTestBot(t, nil)
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) EventMiddleware {
+		return func(next EventHandler) EventHandler {
+			return func(ctx context.Context, event *Event) error {
+				mu.Lock()
+				calls = append(calls, name)
+				mu.Unlock()
+				return next(ctx, event)
+			}
+		}
+	}
+
+	handled := make(chan struct{}, 1)
+	RegisterHandler(b, "stream.online", "1", func(ctx context.Context, api *helix.Client, r Response[helix.EventSubStreamOnlineEvent, helix.EventSubCondition]) error {
+		handled <- struct{}{}
+		return nil
+	})
+
+	b.LoadEventMiddleware([]EventMiddleware{record("global-1"), record("global-2")})
+	b.LoadSubscriptionMiddleware(subscriptionKey("stream.online", "1"), []EventMiddleware{record("typed")})
+	b.LoadSubscriptionMiddleware(subscriptionKey("stream.offline", "1"), []EventMiddleware{record("other-type")})
+	b.LoadEventMiddleware([]EventMiddleware{record("global-3")})
+
+	if w := postWebhook(t, b, "message-1", "notification", streamOnlineBody("streamer")); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	receive(t, handled, "handler")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got, want := strings.Join(calls, ","), "global-1,global-2,global-3,typed"; got != want {
+		t.Errorf("middleware ran in order %s, want %s", got, want)
+	}
+}
+
+func TestEventMiddlewareDropsEvent(t *testing.T) {
+	b := newTestBot(t, nil)
+
+	handled := make(chan string, 2)
+	RegisterHandler(b, "stream.online", "1", func(ctx context.Context, api *helix.Client, r Response[helix.EventSubStreamOnlineEvent, helix.EventSubCondition]) error {
+		handled <- r.Event.BroadcasterUserLogin
+		return nil
+	})
+
+	b.LoadSubscriptionMiddleware(subscriptionKey("stream.online", "1"), []EventMiddleware{
+		func(next EventHandler) EventHandler {
+			return func(ctx context.Context, event *Event) error {
+				if r, ok := EventData[helix.EventSubStreamOnlineEvent, helix.EventSubCondition](event); ok && r.Event.BroadcasterUserLogin == "ignored" {
+					return nil
+				}
+				return next(ctx, event)
+			}
+		},
+	})
+
+	for i, login := range []string{"ignored", "streamer"} {
+		if w := postWebhook(t, b, "message-"+strconv.Itoa(i), "notification", streamOnlineBody(login)); w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+	}
+
+	if err := b.dispatcher.drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	close(handled)
+
+	var logins []string
+	for login := range handled {
+		logins = append(logins, login)
+	}
+	if len(logins) != 1 || logins[0] != "streamer" {
+		t.Errorf("handled %v, want only streamer", logins)
+	}
+	if stats := b.dispatcher.stats(); stats.Processed != 2 || stats.Failed != 0 {
+		t.Errorf("stats = %+v, want 2 processed and none failed", stats)
+	}
+}
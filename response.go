@@ -17,5 +17,14 @@ type Subscription[T interface{}] struct {
 	Status    Status    `json:"status"`
 	Cost      int       `json:"cost"`
 	Condition T         `json:"condition"`
+	Transport Transport `json:"transport"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// Transport describes how notifications for a subscription are delivered.
+type Transport struct {
+	Method    Method `json:"method"`
+	Callback  string `json:"callback,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+	ConduitID string `json:"conduit_id,omitempty"`
+}
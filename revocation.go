@@ -0,0 +1,171 @@
+package twitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/nicklaw5/helix/v2"
+)
+
+// revocations holds revoked subscriptions waiting for valid credentials before they are recreated.
+type revocations struct {
+	mu      sync.Mutex
+	pending map[string]Subscription[helix.EventSubCondition]
+}
+
+func newRevocations() *revocations {
+	return &revocations{
+		pending: make(map[string]Subscription[helix.EventSubCondition]),
+	}
+}
+
+func (r *revocations) add(sub Subscription[helix.EventSubCondition]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending[sub.ID] = sub
+}
+
+// take removes and returns the pending subscriptions for which match returns true.
+func (r *revocations) take(match func(sub Subscription[helix.EventSubCondition]) bool) []Subscription[helix.EventSubCondition] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var subs []Subscription[helix.EventSubCondition]
+	for id, sub := range r.pending {
+		if match(sub) {
+			subs = append(subs, sub)
+			delete(r.pending, id)
+		}
+	}
+	return subs
+}
+
+// authorizedBy returns a match for subscriptions whose condition names userID,
+// and so may be recreated once that user has logged in.
+func authorizedBy(userID string) func(sub Subscription[helix.EventSubCondition]) bool {
+	return func(sub Subscription[helix.EventSubCondition]) bool {
+		c := sub.Condition
+		for _, id := range []string{c.BroadcasterUserID, c.FromBroadcasterUserID, c.ToBroadcasterUserID, c.ModeratorUserID, c.UserID} {
+			if id != "" && id == userID {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// recoverable reports whether a subscription revoked with status can be recreated.
+//
+// Subscriptions revoked because the user no longer exists or the version was removed cannot.
+func recoverable(status Status) bool {
+	return status == AuthorizationRevoked || status == NotificationFailuresExceeded
+}
+
+// processRevocation notifies the engine of a revoked subscription and, if enabled,
+// schedules it to be recreated.
+//
+// Subscriptions revoked for notification failures are recreated immediately, while those
+// revoked for authorization are recreated when a user named in their condition next logs in.
+func processRevocation(ctx context.Context, meta Metadata, body []byte, b *Bot) error {
+	var wrapper struct {
+		Subscription Subscription[helix.EventSubCondition] `json:"subscription"`
+	}
+
+	if err := json.Unmarshal(body, &wrapper); err != nil {
+		b.logger.Error().Err(err).Msg("failed to unmarshal revocation")
+		return err
+	}
+
+	sub := wrapper.Subscription
+	meta.SubscriptionType = subscriptionKey(sub.Type, sub.Version)
+
+	b.logger.Warn().
+		Str("subscription_id", sub.ID).
+		Str("subscription", string(subscriptionKey(sub.Type, sub.Version))).
+		Str("status", string(sub.Status)).
+		Msg("received subscription revocation")
+
+	if implements[SubscriptionRevokedHandler](b.engine) {
+		b.dispatch(ctx, sub.Condition.BroadcasterUserID, meta, func(ctx context.Context) error {
+			return fanOut(b.engine, func(h SubscriptionRevokedHandler) error {
+				return h.OnSubscriptionRevoked(ctx, b.helix, sub)
+			})
+		})
+	}
+
+	if !b.config.AutoResubscribe || !recoverable(sub.Status) {
+		return nil
+	}
+
+	b.revocations.add(sub)
+
+	if sub.Status == NotificationFailuresExceeded {
+		go b.resubscribe(b.ctx, func(pending Subscription[helix.EventSubCondition]) bool {
+			return pending.ID == sub.ID
+		})
+	}
+	return nil
+}
+
+// resubscribe recreates the pending revoked subscriptions for which match returns true.
+//
+// Subscriptions that fail to be recreated are kept pending, to be retried on a later login.
+func (b *Bot) resubscribe(ctx context.Context, match func(sub Subscription[helix.EventSubCondition]) bool) {
+	for _, sub := range b.revocations.take(match) {
+		if err := b.createSubscription(ctx, sub); err != nil {
+			b.revocations.add(sub)
+			b.logger.Error().
+				Err(err).
+				Str("subscription", string(subscriptionKey(sub.Type, sub.Version))).
+				Msg("failed to resubscribe")
+			continue
+		}
+
+		b.logger.Info().
+			Str("subscription", string(subscriptionKey(sub.Type, sub.Version))).
+			Msg("resubscribed to revoked subscription")
+	}
+}
+
+// createSubscription creates a subscription with the same type, version, condition and
+// transport as sub, using the current websocket session if the transport is a websocket
+// and the conduit manager if it is a conduit.
+func (b *Bot) createSubscription(ctx context.Context, sub Subscription[helix.EventSubCondition]) error {
+	if sub.Transport.Method == Conduit {
+		if err := b.conduits.CreateSubscription(ctx, sub.Transport.ConduitID, sub.Type, sub.Version, sub.Condition); err != nil {
+			return fmt.Errorf("createSubscription: %w", err)
+		}
+		return nil
+	}
+
+	transport := helix.EventSubTransport{
+		Method: string(sub.Transport.Method),
+	}
+
+	// webhook subscriptions need an app access token
+	api := b.helix
+	switch sub.Transport.Method {
+	case Webhook:
+		transport.Callback = sub.Transport.Callback
+		transport.Secret = b.secret("CLIENT_SECRET")
+		api = b.app
+	case Websocket:
+		transport.SessionID = b.websocket.Session().ID
+	}
+
+	resp, err := api.CreateEventSubSubscription(&helix.EventSubSubscription{
+		Type:      sub.Type,
+		Version:   sub.Version,
+		Condition: sub.Condition,
+		Transport: transport,
+	})
+	if err != nil {
+		return fmt.Errorf("createSubscription: %w", err)
+	}
+	if resp.ErrorMessage != "" {
+		return fmt.Errorf("createSubscription: %d %s", resp.StatusCode, resp.ErrorMessage)
+	}
+	return nil
+}
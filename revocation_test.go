@@ -0,0 +1,99 @@
+package twitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/Etwodev/twitchgo/pkg/config"
+	"github.com/nicklaw5/helix/v2"
+)
+
+func TestResubscribeWaitsForAuthorizingUser(t *testing.T) {
+	cfg := config.Default()
+	cfg.AutoResubscribe = true
+	b := newTestBot(t, nil, WithConfig(cfg))
+
+	var mu sync.Mutex
+	status := http.StatusBadRequest
+	f := newFakeHelix(t)
+	f.handle("POST /eventsub/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if status != http.StatusAccepted {
+			writeJSON(w, status, map[string]interface{}{"error": "Bad Request", "status": status, "message": "try again"})
+			return
+		}
+		writeJSON(w, status, helix.ManyEventSubSubscriptions{})
+	})
+	b.app = f.client()
+
+	body := `{"subscription":{"id":"sub-1","status":"authorization_revoked","type":"stream.online","version":"1",` +
+		`"condition":{"broadcaster_user_id":"1234"},"transport":{"method":"webhook","callback":"` + testCallback + `"}}}`
+	if w := postWebhook(t, b, "revocation-1", "revocation", body); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	// another user logging in leaves the subscription pending
+	b.resubscribe(context.Background(), authorizedBy("5678"))
+	if n := len(f.received("POST /eventsub/subscriptions")); n != 0 {
+		t.Fatalf("created %d subscriptions after another user's login, want 0", n)
+	}
+
+	// a failed attempt keeps it pending for the next login
+	b.resubscribe(context.Background(), authorizedBy("1234"))
+	if n := len(f.received("POST /eventsub/subscriptions")); n != 1 {
+		t.Fatalf("created %d subscriptions, want 1 attempt", n)
+	}
+
+	mu.Lock()
+	status = http.StatusAccepted
+	mu.Unlock()
+
+	b.resubscribe(context.Background(), authorizedBy("1234"))
+	created := createdSubscriptions(t, f)
+	if len(created) != 2 {
+		t.Fatalf("created %d subscriptions, want the failed one retried", len(created))
+	}
+	if sub := created[1]; sub.Type != "stream.online" || sub.Condition.BroadcasterUserID != "1234" || sub.Transport.Callback != testCallback {
+		t.Errorf("created %+v, want stream.online for 1234 delivered to the callback", sub)
+	}
+
+	b.resubscribe(context.Background(), authorizedBy("1234"))
+	if n := len(f.received("POST /eventsub/subscriptions")); n != 2 {
+		t.Errorf("created %d subscriptions, want none after it was recreated", n)
+	}
+}
+
+func TestResubscribeRecreatesConduitSubscription(t *testing.T) {
+	cfg := config.Default()
+	cfg.AutoResubscribe = true
+	b := newTestBot(t, nil, WithConfig(cfg))
+
+	created := make(chan Transport, 1)
+	f := newFakeHelix(t)
+	f.handle("POST /eventsub/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Transport Transport `json:"transport"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{"data": []interface{}{}})
+		created <- body.Transport
+	})
+	b.conduits = NewConduitManager(b, f.client(), f.URL)
+
+	body := `{"subscription":{"id":"sub-1","status":"notification_failures_exceeded","type":"stream.online","version":"1",` +
+		`"condition":{"broadcaster_user_id":"1234"},"transport":{"method":"conduit","conduit_id":"conduit-1"}}}`
+	if w := postWebhook(t, b, "revocation-1", "revocation", body); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if transport := receive(t, created, "conduit subscription recreated"); transport.Method != Conduit || transport.ConduitID != "conduit-1" {
+		t.Errorf("created with transport %+v, want conduit-1", transport)
+	}
+}
@@ -0,0 +1,190 @@
+package twitchgo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nicklaw5/helix/v2"
+)
+
+const (
+	RedemptionFulfilled = "FULFILLED"
+	RedemptionCanceled  = "CANCELED"
+)
+
+// FulfillRedemption marks a custom reward redemption as fulfilled.
+//
+// Only redemptions of rewards created by this client ID can be updated, and the
+// broadcaster must have logged in, as they are made with the broadcaster's token.
+//
+// Example:
+//
+//	err := bot.FulfillRedemption(event.Event.BroadcasterUserID, event.Event.Reward.ID, event.Event.ID)
+func (b *Bot) FulfillRedemption(broadcasterID, rewardID, redemptionID string) error {
+	return b.updateRedemptionStatus(broadcasterID, rewardID, redemptionID, RedemptionFulfilled)
+}
+
+// CancelRedemption marks a custom reward redemption as canceled, refunding the viewer's points.
+//
+// Only redemptions of rewards created by this client ID can be updated, and the
+// broadcaster must have logged in, as they are made with the broadcaster's token.
+//
+// Example:
+//
+//	err := bot.CancelRedemption(event.Event.BroadcasterUserID, event.Event.Reward.ID, event.Event.ID)
+func (b *Bot) CancelRedemption(broadcasterID, rewardID, redemptionID string) error {
+	return b.updateRedemptionStatus(broadcasterID, rewardID, redemptionID, RedemptionCanceled)
+}
+
+func (b *Bot) updateRedemptionStatus(broadcasterID, rewardID, redemptionID, status string) error {
+	api, err := b.HelixFor(broadcasterID)
+	if err != nil {
+		return fmt.Errorf("updateRedemptionStatus: %w", err)
+	}
+
+	resp, err := api.UpdateChannelCustomRewardsRedemptionStatus(&helix.UpdateChannelCustomRewardsRedemptionStatusParams{
+		ID:            redemptionID,
+		BroadcasterID: broadcasterID,
+		RewardID:      rewardID,
+		Status:        status,
+	})
+	if err != nil {
+		return fmt.Errorf("updateRedemptionStatus: failed updating redemption: %w", err)
+	}
+	if resp.ErrorMessage != "" {
+		return fmt.Errorf("updateRedemptionStatus: failed updating redemption: %d %s", resp.StatusCode, resp.ErrorMessage)
+	}
+	return nil
+}
+
+// SyncCustomRewards declaratively reconciles the broadcaster's custom rewards against rewards.
+//
+// Rewards are matched by title. Missing rewards are created and rewards created by this
+// client ID are updated if they differ. Rewards created by other applications or by the
+// broadcaster are left alone, as are rewards of ours that are not in the desired set.
+//
+// Rewards are managed with the broadcaster's token, so it returns ErrTokenNotFound if the
+// broadcaster has not logged in.
+//
+// It is intended to be called from OnBotStart.
+//
+// Example:
+//
+//	err := bot.SyncCustomRewards(broadcasterID, []helix.ChannelCustomRewardsParams{
+//	    {Title: "Hydrate", Cost: 500, IsEnabled: true},
+//	})
+func (b *Bot) SyncCustomRewards(broadcasterID string, rewards []helix.ChannelCustomRewardsParams) error {
+	api, err := b.HelixFor(broadcasterID)
+	if err != nil {
+		return fmt.Errorf("SyncCustomRewards: %w", err)
+	}
+
+	all, err := getCustomRewards(api, broadcasterID, false)
+	if err != nil {
+		return fmt.Errorf("SyncCustomRewards: %w", err)
+	}
+
+	manageable, err := getCustomRewards(api, broadcasterID, true)
+	if err != nil {
+		return fmt.Errorf("SyncCustomRewards: %w", err)
+	}
+
+	owned := make(map[string]bool, len(manageable))
+	for _, r := range manageable {
+		owned[r.ID] = true
+	}
+
+	existing := make(map[string]helix.ChannelCustomReward, len(all))
+	for _, r := range all {
+		existing[strings.ToLower(r.Title)] = r
+	}
+
+	for _, desired := range rewards {
+		desired.BroadcasterID = broadcasterID
+
+		current, ok := existing[strings.ToLower(desired.Title)]
+		if !ok {
+			resp, err := api.CreateCustomReward(&desired)
+			if err != nil {
+				return fmt.Errorf("SyncCustomRewards: failed creating reward %q: %w", desired.Title, err)
+			}
+			if resp.ErrorMessage != "" {
+				return fmt.Errorf("SyncCustomRewards: failed creating reward %q: %d %s", desired.Title, resp.StatusCode, resp.ErrorMessage)
+			}
+			b.logger.Info().Str("broadcaster_id", broadcasterID).Str("title", desired.Title).Msg("created custom reward")
+			continue
+		}
+
+		if !owned[current.ID] {
+			b.logger.Warn().Str("broadcaster_id", broadcasterID).Str("title", desired.Title).Msg("custom reward owned by another application; skipping")
+			continue
+		}
+
+		if !rewardChanged(current, desired) {
+			continue
+		}
+
+		resp, err := api.UpdateCustomReward(&helix.UpdateChannelCustomRewardsParams{
+			ID:                                current.ID,
+			BroadcasterID:                     broadcasterID,
+			Title:                             desired.Title,
+			Cost:                              desired.Cost,
+			Prompt:                            desired.Prompt,
+			IsEnabled:                         desired.IsEnabled,
+			BackgroundColor:                   desired.BackgroundColor,
+			IsUserInputRequired:               desired.IsUserInputRequired,
+			IsMaxPerStreamEnabled:             desired.IsMaxPerStreamEnabled,
+			MaxPerStream:                      desired.MaxPerStream,
+			IsMaxPerUserPerStreamEnabled:      desired.IsMaxPerUserPerStreamEnabled,
+			MaxPerUserPerStream:               desired.MaxPerUserPerStream,
+			IsGlobalCooldownEnabled:           desired.IsGlobalCooldownEnabled,
+			GlobalCooldownSeconds:             desired.GlobalCooldownSeconds,
+			ShouldRedemptionsSkipRequestQueue: desired.ShouldRedemptionsSkipRequestQueue,
+		})
+		if err != nil {
+			return fmt.Errorf("SyncCustomRewards: failed updating reward %q: %w", desired.Title, err)
+		}
+		if resp.ErrorMessage != "" {
+			return fmt.Errorf("SyncCustomRewards: failed updating reward %q: %d %s", desired.Title, resp.StatusCode, resp.ErrorMessage)
+		}
+		b.logger.Info().Str("broadcaster_id", broadcasterID).Str("title", desired.Title).Msg("updated custom reward")
+	}
+
+	return nil
+}
+
+// getCustomRewards lists the broadcaster's custom rewards through api, only those created
+// by this client ID if manageable is set.
+func getCustomRewards(api *helix.Client, broadcasterID string, manageable bool) ([]helix.ChannelCustomReward, error) {
+	resp, err := api.GetCustomRewards(&helix.GetCustomRewardsParams{
+		BroadcasterID:         broadcasterID,
+		OnlyManageableRewards: manageable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing rewards: %w", err)
+	}
+	if resp.ErrorMessage != "" {
+		return nil, fmt.Errorf("failed listing rewards: %d %s", resp.StatusCode, resp.ErrorMessage)
+	}
+	return resp.Data.ChannelCustomRewards, nil
+}
+
+// rewardChanged reports whether the existing reward differs from the desired parameters.
+func rewardChanged(current helix.ChannelCustomReward, desired helix.ChannelCustomRewardsParams) bool {
+	if desired.BackgroundColor != "" && !strings.EqualFold(current.BackgroundColor, desired.BackgroundColor) {
+		return true
+	}
+
+	return current.Title != desired.Title ||
+		current.Cost != desired.Cost ||
+		current.Prompt != desired.Prompt ||
+		current.IsEnabled != desired.IsEnabled ||
+		current.IsUserInputRequired != desired.IsUserInputRequired ||
+		current.MaxPerStreamSetting.IsEnabled != desired.IsMaxPerStreamEnabled ||
+		(desired.IsMaxPerStreamEnabled && current.MaxPerStreamSetting.MaxPerStream != desired.MaxPerStream) ||
+		current.MaxPerUserPerStreamSetting.IsEnabled != desired.IsMaxPerUserPerStreamEnabled ||
+		(desired.IsMaxPerUserPerStreamEnabled && current.MaxPerUserPerStreamSetting.MaxPerUserPerStream != desired.MaxPerUserPerStream) ||
+		current.GlobalCooldownSetting.IsEnabled != desired.IsGlobalCooldownEnabled ||
+		(desired.IsGlobalCooldownEnabled && current.GlobalCooldownSetting.GlobalCooldownSeconds != desired.GlobalCooldownSeconds) ||
+		current.ShouldRedemptionsSkipRequestQueue != desired.ShouldRedemptionsSkipRequestQueue
+}
@@ -0,0 +1,118 @@
+package twitchgo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/nicklaw5/helix/v2"
+)
+
+func TestSyncCustomRewards(t *testing.T) {
+	b := newTestBot(t, nil)
+	f := newFakeHelix(t)
+
+	rewards := []helix.ChannelCustomReward{
+		{ID: "hydrate", Title: "Hydrate", Cost: 100, IsEnabled: true},
+		{ID: "stretch", Title: "Stretch", Cost: 200, IsEnabled: true},
+		{ID: "foreign", Title: "Highlight", Cost: 300, IsEnabled: true},
+	}
+	f.handle("GET /channel_points/custom_rewards", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("broadcaster_id") != "1234" {
+			t.Errorf("listed rewards of %q, want 1234", r.URL.Query().Get("broadcaster_id"))
+		}
+
+		listed := rewards
+		if r.URL.Query().Get("only_manageable_rewards") == "true" {
+			listed = rewards[:2]
+		}
+		writeJSON(w, http.StatusOK, helix.ManyChannelCustomRewards{ChannelCustomRewards: listed})
+	})
+	f.handle("POST /channel_points/custom_rewards", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, helix.ManyChannelCustomRewards{ChannelCustomRewards: []helix.ChannelCustomReward{{ID: "created"}}})
+	})
+	f.handle("PATCH /channel_points/custom_rewards", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, helix.ManyChannelCustomRewards{ChannelCustomRewards: []helix.ChannelCustomReward{{ID: r.URL.Query().Get("id")}}})
+	})
+
+	// the broadcaster has logged in, so rewards are managed with their client
+	b.users.mu.Lock()
+	b.users.clients["1234"] = f.client()
+	b.users.mu.Unlock()
+
+	err := b.SyncCustomRewards("1234", []helix.ChannelCustomRewardsParams{
+		{Title: "hydrate", Cost: 500, IsEnabled: true},
+		{Title: "Stretch", Cost: 200, IsEnabled: true},
+		{Title: "Highlight", Cost: 1000, IsEnabled: true},
+		{Title: "Dance", Cost: 50, IsEnabled: true},
+	})
+	if err != nil {
+		t.Fatalf("SyncCustomRewards: %v", err)
+	}
+
+	created := f.received("POST /channel_points/custom_rewards")
+	if len(created) != 1 {
+		t.Fatalf("created %d rewards, want 1", len(created))
+	}
+	var reward helix.ChannelCustomRewardsParams
+	if err := json.Unmarshal(created[0].Body, &reward); err != nil {
+		t.Fatalf("decoding created reward: %v", err)
+	}
+	if broadcaster := url.Values(created[0].Query).Get("broadcaster_id"); reward.Title != "Dance" || reward.Cost != 50 || broadcaster != "1234" {
+		t.Errorf("created %+v for %q, want Dance costing 50 for 1234", reward, broadcaster)
+	}
+
+	// Stretch is unchanged and Highlight belongs to another application
+	updated := f.received("PATCH /channel_points/custom_rewards")
+	if len(updated) != 1 {
+		t.Fatalf("updated %d rewards, want 1", len(updated))
+	}
+	if err := json.Unmarshal(updated[0].Body, &reward); err != nil {
+		t.Fatalf("decoding updated reward: %v", err)
+	}
+	if id := updated[0].Query["id"]; len(id) != 1 || id[0] != "hydrate" || reward.Cost != 500 {
+		t.Errorf("updated %v to %+v, want hydrate costing 500", id, reward)
+	}
+}
+
+func TestSyncCustomRewardsNeedsBroadcasterLogin(t *testing.T) {
+	b := newTestBot(t, nil)
+
+	err := b.SyncCustomRewards("1234", []helix.ChannelCustomRewardsParams{{Title: "Hydrate", Cost: 500}})
+	if !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("SyncCustomRewards: %v, want ErrTokenNotFound", err)
+	}
+}
+
+func TestFulfillRedemption(t *testing.T) {
+	b := newTestBot(t, nil)
+	f := newFakeHelix(t)
+	f.handle("PATCH /channel_points/custom_rewards/redemptions", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, helix.ManyChannelCustomRewardsRedemptions{})
+	})
+
+	b.users.mu.Lock()
+	b.users.clients["1234"] = f.client()
+	b.users.mu.Unlock()
+
+	if err := b.FulfillRedemption("1234", "reward-1", "redemption-1"); err != nil {
+		t.Fatalf("FulfillRedemption: %v", err)
+	}
+
+	requests := f.received("PATCH /channel_points/custom_rewards/redemptions")
+	if len(requests) != 1 {
+		t.Fatalf("updated %d redemptions, want 1", len(requests))
+	}
+	q := url.Values(requests[0].Query)
+	if q.Get("id") != "redemption-1" || q.Get("reward_id") != "reward-1" || q.Get("broadcaster_id") != "1234" {
+		t.Errorf("updated redemption %v, want redemption-1 of reward-1 for 1234", q)
+	}
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(requests[0].Body, &body); err != nil || body.Status != RedemptionFulfilled {
+		t.Errorf("status = %q (%v), want %s", body.Status, err, RedemptionFulfilled)
+	}
+}
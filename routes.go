@@ -1,7 +1,7 @@
 package twitchgo
 
 import (
-	"os"
+	"net/http"
 
 	"github.com/Etwodev/twitchgo/pkg/helpers"
 	"github.com/go-chi/chi/v5"
@@ -15,13 +15,17 @@ func (b *Bot) Routes(m *chi.Mux) {
 
 	m.Post("/webhook/callback", b.Handle)
 	m.Route("/auth", func(r chi.Router) {
-		r.Get("/login", HandleLogin)
-		r.Get("/callback", helpers.SimpleBasicAuth(
-			os.Getenv("CALLBACK_USER"),
-			os.Getenv("CALLBACK_PASS"),
-			b.HandleCallback,
-		))
+		r.Get("/login", b.HandleLogin)
+		r.Get("/callback", b.callbackAuth(b.HandleCallback))
 	})
 
 	m.Get("/healthcheck", HandleHealthCheck)
 }
+
+// callbackAuth guards next with basic auth, looking up CALLBACK_USER and CALLBACK_PASS
+// on each request so rotated credentials apply without a restart.
+func (b *Bot) callbackAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		helpers.SimpleBasicAuth(b.secret("CALLBACK_USER"), b.secret("CALLBACK_PASS"), next)(w, r)
+	}
+}
@@ -0,0 +1,53 @@
+package twitchgo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestCallbackAuthUsesRotatedCredentials(t *testing.T) {
+	var mu sync.Mutex
+	password := "first"
+	b := newTestBot(t, nil, WithSecretSource(func(name string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		switch name {
+		case "CALLBACK_USER":
+			return "admin"
+		case "CALLBACK_PASS":
+			return password
+		}
+		return "test-" + name
+	}))
+
+	m := chi.NewMux()
+	b.Routes(m)
+
+	callback := func(pass string) int {
+		r := httptest.NewRequest(http.MethodGet, "/auth/callback", nil)
+		r.SetBasicAuth("admin", pass)
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// past the credentials, the callback rejects the request for its missing state
+	if code := callback("first"); code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", code, http.StatusBadRequest)
+	}
+
+	mu.Lock()
+	password = "second"
+	mu.Unlock()
+
+	if code := callback("first"); code != http.StatusUnauthorized {
+		t.Errorf("status with the old password = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := callback("second"); code != http.StatusBadRequest {
+		t.Errorf("status with the rotated password = %d, want %d", code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,380 @@
+package twitchgo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nicklaw5/helix/v2"
+)
+
+// defaultSyncInterval is how often Run reconciles when given a non-positive interval.
+const defaultSyncInterval = 5 * time.Minute
+
+// SubscriptionSpec declares a desired EventSub subscription.
+//
+// For webhook transports an empty Callback defaults to the configured callbackUrl,
+// and for websocket transports an empty SessionID defaults to the current session.
+// Conduit transports must set ConduitID.
+type SubscriptionSpec struct {
+	Type      string
+	Version   string
+	Condition helix.EventSubCondition
+	Transport Transport
+}
+
+// SubscriptionState is the outcome of the most recent reconciliation.
+type SubscriptionState struct {
+	Subscriptions []helix.EventSubSubscription // our subscriptions after reconciling
+	Created       int                          // number of subscriptions created
+	Deleted       int                          // number of subscriptions deleted
+	ReconciledAt  time.Time                    // when reconciliation finished
+	Err           error                        // the error that stopped reconciliation, if any
+}
+
+// SubscriptionManager reconciles the EventSub subscriptions that exist on Twitch
+// against a declared set of desired subscriptions.
+type SubscriptionManager struct {
+	bot      *Bot
+	api      *helix.Client
+	app      *helix.Client // if set, manages webhook subscriptions, which need an app access token
+	callback string
+	mu       sync.RWMutex
+	desired  []SubscriptionSpec
+	state    SubscriptionState
+	run      sync.Mutex
+}
+
+// NewSubscriptionManager creates a SubscriptionManager that manages subscriptions through api.
+//
+// callback is the public URL of the webhook endpoint; subscriptions pointing at it
+// are considered ours.
+//
+// Example:
+//
+//	m := twitchgo.NewSubscriptionManager(bot, bot.Helix(), "https://example.com/webhook/callback")
+func NewSubscriptionManager(b *Bot, api *helix.Client, callback string) *SubscriptionManager {
+	return &SubscriptionManager{
+		bot:      b,
+		api:      api,
+		callback: callback,
+	}
+}
+
+// Declare replaces the desired set of subscriptions.
+//
+// Example:
+//
+//	bot.Subscriptions().Declare(twitchgo.SubscriptionSpec{
+//	    Type:      "stream.online",
+//	    Version:   "1",
+//	    Condition: helix.EventSubCondition{BroadcasterUserID: "1234"},
+//	    Transport: twitchgo.Transport{Method: twitchgo.Webhook},
+//	})
+func (m *SubscriptionManager) Declare(specs ...SubscriptionSpec) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.desired = append([]SubscriptionSpec{}, specs...)
+}
+
+// Desired returns the declared set of subscriptions.
+func (m *SubscriptionManager) Desired() []SubscriptionSpec {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]SubscriptionSpec{}, m.desired...)
+}
+
+// State returns the outcome of the most recent reconciliation.
+func (m *SubscriptionManager) State() SubscriptionState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// Run reconciles immediately and then every interval until ctx is cancelled.
+// A non-positive interval reconciles every five minutes.
+func (m *SubscriptionManager) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultSyncInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.Reconcile(ctx); err != nil {
+			m.bot.logger.Error().Err(err).Msg("failed to reconcile subscriptions")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile lists existing subscriptions, creates missing ones and deletes ours that are
+// stale, failed or no longer desired. Subscriptions belonging to other callbacks or
+// sessions are left alone.
+func (m *SubscriptionManager) Reconcile(ctx context.Context) error {
+	m.run.Lock()
+	defer m.run.Unlock()
+
+	state := SubscriptionState{}
+	err := m.reconcile(ctx, &state)
+	state.Err = err
+	state.ReconciledAt = m.bot.now()
+
+	m.mu.Lock()
+	m.state = state
+	m.mu.Unlock()
+
+	m.bot.logger.Debug().
+		Int("created", state.Created).
+		Int("deleted", state.Deleted).
+		Int("subscriptions", len(state.Subscriptions)).
+		Msg("reconciled subscriptions")
+
+	return err
+}
+
+func (m *SubscriptionManager) reconcile(ctx context.Context, state *SubscriptionState) error {
+	desired := m.Desired()
+	for i := range desired {
+		desired[i] = m.resolve(desired[i])
+	}
+
+	existing, err := m.list()
+	if err != nil {
+		return fmt.Errorf("Reconcile: %w", err)
+	}
+
+	var conduits map[string]string
+	for _, spec := range desired {
+		if spec.Transport.Method == Conduit {
+			if conduits, err = m.bot.conduits.subscriptionConduits(ctx); err != nil {
+				return fmt.Errorf("Reconcile: %w", err)
+			}
+			break
+		}
+	}
+
+	satisfied := make([]bool, len(desired))
+	for _, sub := range existing {
+		// conduits may be shared with other bots, so conduit subscriptions are matched but never deleted
+		isConduit := Method(sub.Transport.Method) == Conduit
+		if !isConduit && !m.owns(sub) {
+			continue
+		}
+
+		match := -1
+		if sub.Status == helix.EventSubStatusEnabled || sub.Status == helix.EventSubStatusPending {
+			for i, spec := range desired {
+				if !satisfied[i] && specMatches(spec, sub, conduits[sub.ID]) {
+					match = i
+					break
+				}
+			}
+		}
+
+		if match >= 0 {
+			satisfied[match] = true
+			state.Subscriptions = append(state.Subscriptions, sub)
+			continue
+		}
+
+		if isConduit {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		resp, err := m.apiFor(Method(sub.Transport.Method)).RemoveEventSubSubscription(sub.ID)
+		if err != nil {
+			return fmt.Errorf("Reconcile: failed deleting subscription %s: %w", sub.ID, err)
+		}
+		if resp.ErrorMessage != "" {
+			return fmt.Errorf("Reconcile: failed deleting subscription %s: %d %s", sub.ID, resp.StatusCode, resp.ErrorMessage)
+		}
+
+		state.Deleted++
+		m.bot.logger.Info().
+			Str("subscription_id", sub.ID).
+			Str("subscription", string(subscriptionKey(sub.Type, sub.Version))).
+			Str("status", sub.Status).
+			Msg("deleted subscription")
+	}
+
+	for i, spec := range desired {
+		if satisfied[i] {
+			continue
+		}
+
+		// websocket subscriptions are created once a session is established
+		if spec.Transport.Method == Websocket && spec.Transport.SessionID == "" {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if spec.Transport.Method == Conduit {
+			if err := m.bot.conduits.CreateSubscription(ctx, spec.Transport.ConduitID, spec.Type, spec.Version, spec.Condition); err != nil {
+				return fmt.Errorf("Reconcile: failed creating subscription %s: %w", subscriptionKey(spec.Type, spec.Version), err)
+			}
+
+			state.Created++
+			m.bot.logger.Info().
+				Str("subscription", string(subscriptionKey(spec.Type, spec.Version))).
+				Msg("created subscription")
+			continue
+		}
+
+		transport := helix.EventSubTransport{
+			Method:    string(spec.Transport.Method),
+			Callback:  spec.Transport.Callback,
+			SessionID: spec.Transport.SessionID,
+		}
+		if spec.Transport.Method == Webhook {
+			transport.Secret = m.bot.secret("CLIENT_SECRET")
+		}
+
+		resp, err := m.apiFor(spec.Transport.Method).CreateEventSubSubscription(&helix.EventSubSubscription{
+			Type:      spec.Type,
+			Version:   spec.Version,
+			Condition: spec.Condition,
+			Transport: transport,
+		})
+		if err != nil {
+			return fmt.Errorf("Reconcile: failed creating subscription %s: %w", subscriptionKey(spec.Type, spec.Version), err)
+		}
+		if resp.ErrorMessage != "" {
+			return fmt.Errorf("Reconcile: failed creating subscription %s: %d %s", subscriptionKey(spec.Type, spec.Version), resp.StatusCode, resp.ErrorMessage)
+		}
+
+		state.Created++
+		state.Subscriptions = append(state.Subscriptions, resp.Data.EventSubSubscriptions...)
+		m.bot.logger.Info().
+			Str("subscription", string(subscriptionKey(spec.Type, spec.Version))).
+			Msg("created subscription")
+	}
+
+	return nil
+}
+
+// list returns every subscription visible to the user and app clients, following pagination.
+func (m *SubscriptionManager) list() ([]helix.EventSubSubscription, error) {
+	apis := []*helix.Client{m.api}
+	if m.app != nil && m.app != m.api {
+		apis = append(apis, m.app)
+	}
+
+	var subs []helix.EventSubSubscription
+	seen := make(map[string]bool)
+	for _, api := range apis {
+		listed, err := listSubscriptions(api)
+		if err != nil {
+			return nil, err
+		}
+
+		// without a user token both clients use the app access token and list the same subscriptions
+		for _, sub := range listed {
+			if !seen[sub.ID] {
+				seen[sub.ID] = true
+				subs = append(subs, sub)
+			}
+		}
+	}
+	return subs, nil
+}
+
+// listSubscriptions returns every subscription visible to api, following pagination.
+func listSubscriptions(api *helix.Client) ([]helix.EventSubSubscription, error) {
+	var subs []helix.EventSubSubscription
+	params := &helix.EventSubSubscriptionsParams{}
+
+	for {
+		resp, err := api.GetEventSubSubscriptions(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed listing subscriptions: %w", err)
+		}
+		if resp.ErrorMessage != "" {
+			return nil, fmt.Errorf("failed listing subscriptions: %d %s", resp.StatusCode, resp.ErrorMessage)
+		}
+
+		subs = append(subs, resp.Data.EventSubSubscriptions...)
+
+		if resp.Data.Pagination.Cursor == "" {
+			return subs, nil
+		}
+		params.After = resp.Data.Pagination.Cursor
+	}
+}
+
+// apiFor returns the client managing subscriptions delivered by method.
+func (m *SubscriptionManager) apiFor(method Method) *helix.Client {
+	if method == Webhook && m.app != nil {
+		return m.app
+	}
+	return m.api
+}
+
+// resolve fills in transport defaults for spec.
+func (m *SubscriptionManager) resolve(spec SubscriptionSpec) SubscriptionSpec {
+	switch spec.Transport.Method {
+	case Webhook:
+		if spec.Transport.Callback == "" {
+			spec.Transport.Callback = m.callback
+		}
+	case Websocket:
+		if spec.Transport.SessionID == "" {
+			spec.Transport.SessionID = m.bot.websocket.Session().ID
+		}
+	}
+	return spec
+}
+
+// owns reports whether sub delivers to our webhook callback or websocket session.
+func (m *SubscriptionManager) owns(sub helix.EventSubSubscription) bool {
+	switch Method(sub.Transport.Method) {
+	case Webhook:
+		if m.callback != "" && sub.Transport.Callback == m.callback {
+			return true
+		}
+		for _, spec := range m.Desired() {
+			if spec.Transport.Method == Webhook && spec.Transport.Callback != "" && spec.Transport.Callback == sub.Transport.Callback {
+				return true
+			}
+		}
+	case Websocket:
+		id := m.bot.websocket.Session().ID
+		return id != "" && sub.Transport.SessionID == id
+	}
+	return false
+}
+
+// specMatches reports whether sub, delivered to conduitID if it is a conduit subscription,
+// satisfies spec.
+func specMatches(spec SubscriptionSpec, sub helix.EventSubSubscription, conduitID string) bool {
+	if subscriptionKey(spec.Type, spec.Version) != subscriptionKey(sub.Type, sub.Version) {
+		return false
+	}
+	if spec.Condition != sub.Condition || string(spec.Transport.Method) != sub.Transport.Method {
+		return false
+	}
+
+	switch spec.Transport.Method {
+	case Webhook:
+		return spec.Transport.Callback == sub.Transport.Callback
+	case Websocket:
+		return spec.Transport.SessionID == sub.Transport.SessionID
+	case Conduit:
+		return spec.Transport.ConduitID == conduitID
+	}
+	return true
+}
@@ -0,0 +1,271 @@
+package twitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/nicklaw5/helix/v2"
+)
+
+const testCallback = "https://example.com/webhook/callback"
+
+// serveSubscriptions answers the EventSub subscription endpoints of f, listing pages in
+// order, accepting every create and deleting any subscription.
+func serveSubscriptions(f *fakeHelix, pages ...[]helix.EventSubSubscription) {
+	var mu sync.Mutex
+	created := 0
+
+	f.handle("GET /eventsub/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("after"))
+
+		var body helix.ManyEventSubSubscriptions
+		if page < len(pages) {
+			body.EventSubSubscriptions = pages[page]
+		}
+		if page+1 < len(pages) {
+			body.Pagination.Cursor = strconv.Itoa(page + 1)
+		}
+		writeJSON(w, http.StatusOK, body)
+	})
+
+	f.handle("POST /eventsub/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		var sub helix.EventSubSubscription
+		if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		created++
+		sub.ID = "created-" + strconv.Itoa(created)
+		mu.Unlock()
+
+		sub.Status = helix.EventSubStatusPending
+		sub.Transport.Secret = ""
+		writeJSON(w, http.StatusAccepted, helix.ManyEventSubSubscriptions{
+			EventSubSubscriptions: []helix.EventSubSubscription{sub},
+		})
+	})
+
+	f.handle("DELETE /eventsub/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// webhookSubscription returns a subscription to type delivered to callback.
+func webhookSubscription(id, status, typ, broadcaster, callback string) helix.EventSubSubscription {
+	return helix.EventSubSubscription{
+		ID:        id,
+		Status:    status,
+		Type:      typ,
+		Version:   "1",
+		Condition: helix.EventSubCondition{BroadcasterUserID: broadcaster},
+		Transport: helix.EventSubTransport{Method: string(Webhook), Callback: callback},
+	}
+}
+
+// webhookSpec declares a subscription to type delivered to the configured callback.
+func webhookSpec(typ, broadcaster string) SubscriptionSpec {
+	return SubscriptionSpec{
+		Type:      typ,
+		Version:   "1",
+		Condition: helix.EventSubCondition{BroadcasterUserID: broadcaster},
+		Transport: Transport{Method: Webhook},
+	}
+}
+
+// deletedIDs returns the sorted ids of the subscriptions deleted through f.
+func deletedIDs(f *fakeHelix) []string {
+	var ids []string
+	for _, r := range f.received("DELETE /eventsub/subscriptions") {
+		ids = append(ids, r.Query["id"]...)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// createdSubscriptions returns the subscriptions created through f.
+func createdSubscriptions(t *testing.T, f *fakeHelix) []helix.EventSubSubscription {
+	t.Helper()
+
+	var subs []helix.EventSubSubscription
+	for _, r := range f.received("POST /eventsub/subscriptions") {
+		var sub helix.EventSubSubscription
+		if err := json.Unmarshal(r.Body, &sub); err != nil {
+			t.Fatalf("decoding created subscription: %v", err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+func TestReconcileCreatesMissing(t *testing.T) {
+	b := newTestBot(t, nil)
+	f := newFakeHelix(t)
+	serveSubscriptions(f, []helix.EventSubSubscription{
+		webhookSubscription("existing", helix.EventSubStatusEnabled, "stream.online", "1234", testCallback),
+	})
+
+	m := NewSubscriptionManager(b, f.client(), testCallback)
+	m.Declare(webhookSpec("stream.online", "1234"), webhookSpec("stream.offline", "1234"))
+
+	if err := m.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	created := createdSubscriptions(t, f)
+	if len(created) != 1 {
+		t.Fatalf("created %d subscriptions, want 1", len(created))
+	}
+	sub := created[0]
+	if sub.Type != "stream.offline" || sub.Condition.BroadcasterUserID != "1234" {
+		t.Errorf("created %s for %q, want stream.offline for 1234", sub.Type, sub.Condition.BroadcasterUserID)
+	}
+	if sub.Transport.Callback != testCallback || sub.Transport.Secret != b.secret("CLIENT_SECRET") {
+		t.Errorf("created transport %+v, want the configured callback and secret", sub.Transport)
+	}
+	if ids := deletedIDs(f); len(ids) != 0 {
+		t.Errorf("deleted %v, want nothing deleted", ids)
+	}
+
+	state := m.State()
+	if state.Created != 1 || state.Deleted != 0 || len(state.Subscriptions) != 2 || state.Err != nil {
+		t.Errorf("state = %+v, want 1 created and 2 subscriptions", state)
+	}
+}
+
+func TestReconcileDeletesStaleAndFailed(t *testing.T) {
+	b := newTestBot(t, nil)
+	f := newFakeHelix(t)
+	serveSubscriptions(f, []helix.EventSubSubscription{
+		webhookSubscription("stale", helix.EventSubStatusEnabled, "channel.follow", "1234", testCallback),
+		webhookSubscription("failed", "webhook_callback_verification_failed", "stream.online", "1234", testCallback),
+		webhookSubscription("duplicate-1", helix.EventSubStatusEnabled, "stream.offline", "1234", testCallback),
+		webhookSubscription("duplicate-2", helix.EventSubStatusEnabled, "stream.offline", "1234", testCallback),
+	})
+
+	m := NewSubscriptionManager(b, f.client(), testCallback)
+	m.Declare(webhookSpec("stream.online", "1234"), webhookSpec("stream.offline", "1234"))
+
+	if err := m.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	want := []string{"duplicate-2", "failed", "stale"}
+	if ids := deletedIDs(f); len(ids) != len(want) || ids[0] != want[0] || ids[1] != want[1] || ids[2] != want[2] {
+		t.Errorf("deleted %v, want %v", ids, want)
+	}
+
+	created := createdSubscriptions(t, f)
+	if len(created) != 1 || created[0].Type != "stream.online" {
+		t.Errorf("created %+v, want the failed stream.online subscription recreated", created)
+	}
+
+	if state := m.State(); state.Created != 1 || state.Deleted != 3 {
+		t.Errorf("state = %+v, want 1 created and 3 deleted", state)
+	}
+}
+
+func TestReconcileLeavesForeignSubscriptions(t *testing.T) {
+	b := newTestBot(t, nil)
+	f := newFakeHelix(t)
+	serveSubscriptions(f, []helix.EventSubSubscription{
+		webhookSubscription("other-callback", helix.EventSubStatusEnabled, "channel.follow", "1234", "https://other.example.com/callback"),
+		webhookSubscription("other-failed", "webhook_callback_verification_failed", "stream.online", "1234", "https://other.example.com/callback"),
+		{
+			ID:        "other-session",
+			Status:    helix.EventSubStatusEnabled,
+			Type:      "stream.online",
+			Version:   "1",
+			Condition: helix.EventSubCondition{BroadcasterUserID: "1234"},
+			Transport: helix.EventSubTransport{Method: string(Websocket), SessionID: "someone-else"},
+		},
+	})
+
+	m := NewSubscriptionManager(b, f.client(), testCallback)
+	m.Declare(webhookSpec("stream.online", "1234"))
+
+	if err := m.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	if ids := deletedIDs(f); len(ids) != 0 {
+		t.Errorf("deleted %v, want foreign subscriptions left alone", ids)
+	}
+
+	// neither the other callback's nor the other session's subscription satisfies ours
+	created := createdSubscriptions(t, f)
+	if len(created) != 1 || created[0].Transport.Callback != testCallback {
+		t.Errorf("created %+v, want stream.online created for our callback", created)
+	}
+}
+
+func TestReconcileFollowsPagination(t *testing.T) {
+	b := newTestBot(t, nil)
+	f := newFakeHelix(t)
+	serveSubscriptions(f,
+		[]helix.EventSubSubscription{
+			webhookSubscription("page-1", helix.EventSubStatusEnabled, "stream.online", "1234", testCallback),
+		},
+		[]helix.EventSubSubscription{
+			webhookSubscription("page-2", helix.EventSubStatusEnabled, "stream.offline", "1234", testCallback),
+		},
+		[]helix.EventSubSubscription{
+			webhookSubscription("page-3", helix.EventSubStatusEnabled, "channel.follow", "1234", testCallback),
+		},
+	)
+
+	m := NewSubscriptionManager(b, f.client(), testCallback)
+	m.Declare(webhookSpec("stream.online", "1234"), webhookSpec("stream.offline", "1234"))
+
+	if err := m.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	lists := f.received("GET /eventsub/subscriptions")
+	if len(lists) != 3 {
+		t.Fatalf("listed %d pages, want 3", len(lists))
+	}
+	for i, r := range lists[1:] {
+		if after := r.Query["after"]; len(after) != 1 || after[0] != strconv.Itoa(i+1) {
+			t.Errorf("page %d requested after %v, want cursor %d", i+2, after, i+1)
+		}
+	}
+
+	if created := createdSubscriptions(t, f); len(created) != 0 {
+		t.Errorf("created %+v, want subscriptions on later pages to count", created)
+	}
+	if ids := deletedIDs(f); len(ids) != 1 || ids[0] != "page-3" {
+		t.Errorf("deleted %v, want [page-3]", ids)
+	}
+}
+
+func TestReconcileReportsFailedDelete(t *testing.T) {
+	b := newTestBot(t, nil)
+	f := newFakeHelix(t)
+	serveSubscriptions(f, []helix.EventSubSubscription{
+		webhookSubscription("stale", helix.EventSubStatusEnabled, "channel.follow", "1234", testCallback),
+	})
+	f.handle("DELETE /eventsub/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":   "Bad Request",
+			"status":  http.StatusBadRequest,
+			"message": "something went wrong",
+		})
+	})
+
+	m := NewSubscriptionManager(b, f.client(), testCallback)
+
+	err := m.Reconcile(context.Background())
+	if err == nil {
+		t.Fatal("Reconcile succeeded, want the failed delete reported")
+	}
+	if state := m.State(); state.Err != err || state.Deleted != 0 {
+		t.Errorf("state = %+v, want the error recorded and nothing deleted", state)
+	}
+}
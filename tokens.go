@@ -0,0 +1,411 @@
+package twitchgo
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Etwodev/twitchgo/pkg/config"
+)
+
+// ErrTokenNotFound is returned by a TokenStore when no token is stored for a user.
+var ErrTokenNotFound = errors.New("token not found")
+
+// Token is a user's OAuth credentials.
+type Token struct {
+	UserID       string    `json:"user_id"`
+	Login        string    `json:"login,omitempty"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	Scopes       []string  `json:"scopes,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`    // when the access token expires
+	ObtainedAt   time.Time `json:"obtained_at"`   // when the access token was issued or refreshed
+	Bot          bool      `json:"bot,omitempty"` // whether the user is the bot's own account, acted as by Helix
+}
+
+// TokenStore persists user tokens, keyed by user ID.
+type TokenStore interface {
+	// Load returns the token of a user, or ErrTokenNotFound.
+	Load(ctx context.Context, userID string) (Token, error)
+
+	// Save stores the token of token.UserID, replacing any existing one.
+	Save(ctx context.Context, token Token) error
+
+	// Delete removes the token of a user. Deleting a missing token is not an error.
+	Delete(ctx context.Context, userID string) error
+
+	// List returns every stored token.
+	List(ctx context.Context) ([]Token, error)
+}
+
+// MemoryTokenStore is a TokenStore held in memory, lost when the process exits.
+type MemoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]Token
+}
+
+// NewMemoryTokenStore creates an empty MemoryTokenStore.
+//
+// Example:
+//
+//	store := twitchgo.NewMemoryTokenStore()
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		tokens: make(map[string]Token),
+	}
+}
+
+// Load returns the token of a user, or ErrTokenNotFound.
+func (s *MemoryTokenStore) Load(ctx context.Context, userID string) (Token, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	token, ok := s.tokens[userID]
+	if !ok {
+		return Token{}, ErrTokenNotFound
+	}
+	return token, nil
+}
+
+// Save stores the token of token.UserID, replacing any existing one.
+func (s *MemoryTokenStore) Save(ctx context.Context, token Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[token.UserID] = token
+	return nil
+}
+
+// Delete removes the token of a user.
+func (s *MemoryTokenStore) Delete(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, userID)
+	return nil
+}
+
+// List returns every stored token.
+func (s *MemoryTokenStore) List(ctx context.Context) ([]Token, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tokens := make([]Token, 0, len(s.tokens))
+	for _, token := range s.tokens {
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// FileTokenStore is a TokenStore kept in a file encrypted with AES-256-GCM,
+// readable only by its owner.
+//
+// The file is always written with the first key. The other keys are only used to read
+// a file written before a key rotation, which is re-encrypted with the first key on the next save.
+type FileTokenStore struct {
+	mu   sync.Mutex
+	path string
+	keys []tokenKey
+}
+
+// tokenKey is a key a token file may be encrypted with.
+type tokenKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// tokenFile is the encrypted content of a token file.
+type tokenFile struct {
+	KeyID      string `json:"key_id"`     // the id of the key the tokens were encrypted with
+	Nonce      []byte `json:"nonce"`      // the GCM nonce
+	Ciphertext []byte `json:"ciphertext"` // the encrypted JSON of the tokens, keyed by user id
+}
+
+// NewFileTokenStore creates a FileTokenStore at path, encrypted with the first of keys.
+// Each key must be 32 bytes long. The file is created on the first Save.
+//
+// Example:
+//
+//	keys, err := twitchgo.ParseTokenKeys(os.Getenv("TOKEN_KEYS"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	store, err := twitchgo.NewFileTokenStore("./twitchgo.tokens.json", keys...)
+func NewFileTokenStore(path string, keys ...[]byte) (*FileTokenStore, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("NewFileTokenStore: no encryption key given")
+	}
+
+	s := &FileTokenStore{path: path}
+	for i, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("NewFileTokenStore: key %d is not a 32 byte AES-256 key", i+1)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("NewFileTokenStore: failed creating cipher: %w", err)
+		}
+
+		sum := sha256.Sum256(key)
+		s.keys = append(s.keys, tokenKey{id: hex.EncodeToString(sum[:4]), aead: aead})
+	}
+	return s, nil
+}
+
+// ParseTokenKeys parses base64 encoded keys for a FileTokenStore, separated by commas
+// or newlines, with the key to encrypt with first.
+//
+// Example:
+//
+//	keys, err := twitchgo.ParseTokenKeys(newKey + "," + oldKey)
+func ParseTokenKeys(s string) ([][]byte, error) {
+	var keys [][]byte
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' }) {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		key, err := base64.StdEncoding.DecodeString(field)
+		if err != nil {
+			return nil, fmt.Errorf("ParseTokenKeys: failed decoding key %d: %w", len(keys)+1, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Load returns the token of a user, or ErrTokenNotFound.
+func (s *FileTokenStore) Load(ctx context.Context, userID string) (Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return Token{}, fmt.Errorf("Load: %w", err)
+	}
+
+	token, ok := tokens[userID]
+	if !ok {
+		return Token{}, ErrTokenNotFound
+	}
+	return token, nil
+}
+
+// Save stores the token of token.UserID, replacing any existing one.
+func (s *FileTokenStore) Save(ctx context.Context, token Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return fmt.Errorf("Save: %w", err)
+	}
+
+	tokens[token.UserID] = token
+	if err := s.write(tokens); err != nil {
+		return fmt.Errorf("Save: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the token of a user.
+func (s *FileTokenStore) Delete(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return fmt.Errorf("Delete: %w", err)
+	}
+	if _, ok := tokens[userID]; !ok {
+		return nil
+	}
+
+	delete(tokens, userID)
+	if err := s.write(tokens); err != nil {
+		return fmt.Errorf("Delete: %w", err)
+	}
+	return nil
+}
+
+// List returns every stored token.
+func (s *FileTokenStore) List(ctx context.Context) ([]Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return nil, fmt.Errorf("List: %w", err)
+	}
+
+	list := make([]Token, 0, len(tokens))
+	for _, token := range tokens {
+		list = append(list, token)
+	}
+	return list, nil
+}
+
+// read returns the decrypted tokens in the file, or none if it does not exist.
+func (s *FileTokenStore) read() (map[string]Token, error) {
+	tokens := make(map[string]Token)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed reading token file: %w", err)
+	}
+
+	var file tokenFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed unmarshalling token file: %w", err)
+	}
+
+	var key *tokenKey
+	for i := range s.keys {
+		if s.keys[i].id == file.KeyID {
+			key = &s.keys[i]
+			break
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("token file is encrypted with unknown key %q", file.KeyID)
+	}
+
+	plain, err := key.aead.Open(nil, file.Nonce, file.Ciphertext, []byte(file.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed decrypting token file: %w", err)
+	}
+
+	if err := json.Unmarshal(plain, &tokens); err != nil {
+		return nil, fmt.Errorf("failed unmarshalling tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// write encrypts tokens with the first key and replaces the file, writing to a temporary file first so a
+// crash never leaves it half written.
+func (s *FileTokenStore) write(tokens map[string]Token) error {
+	plain, err := json.Marshal(tokens)
+	if err != nil {
+		return fmt.Errorf("failed marshalling tokens: %w", err)
+	}
+
+	key := s.keys[0]
+	nonce := make([]byte, key.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed generating nonce: %w", err)
+	}
+
+	data, err := json.MarshalIndent(tokenFile{
+		KeyID:      key.id,
+		Nonce:      nonce,
+		Ciphertext: key.aead.Seal(nil, nonce, plain, []byte(key.id)),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed marshalling token file: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed creating token file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed creating token file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed writing token file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed writing token file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed replacing token file: %w", err)
+	}
+	return nil
+}
+
+// tokenKeys returns the token file keys read from the configured tokenKeyFile,
+// or else from the TOKEN_KEYS secret.
+func tokenKeys(cfg *config.Config, secrets SecretSource) ([][]byte, error) {
+	if cfg.TokenKeyFile == "" {
+		return ParseTokenKeys(secrets("TOKEN_KEYS"))
+	}
+
+	data, err := os.ReadFile(cfg.TokenKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading token key file: %w", err)
+	}
+	return ParseTokenKeys(string(data))
+}
+
+// Tokens returns the store the bot persists user tokens in.
+//
+// Example:
+//
+//	token, err := bot.Tokens().Load(ctx, userID)
+func (b *Bot) Tokens() TokenStore {
+	return b.tokens
+}
+
+// resumeTokens sets the helix client to the stored token of the configured botUserId,
+// or else of the user who last logged in as the bot's own account, if any.
+//
+// Tokens stored before the bot's account was recorded are only resumed if there is one,
+// as it cannot be told apart from the broadcasters the bot acts for.
+func (b *Bot) resumeTokens(ctx context.Context) error {
+	tokens, err := b.tokens.List(ctx)
+	if err != nil {
+		return fmt.Errorf("resumeTokens: %w", err)
+	}
+
+	var resume *Token
+	for i, token := range tokens {
+		switch {
+		case b.config.BotUserID != "":
+			if token.UserID == b.config.BotUserID {
+				resume = &tokens[i]
+			}
+		case token.Bot || len(tokens) == 1:
+			if resume == nil || token.ObtainedAt.After(resume.ObtainedAt) {
+				resume = &tokens[i]
+			}
+		}
+	}
+	if resume == nil {
+		if b.config.BotUserID == "" && len(tokens) > 1 {
+			b.logger.Warn().
+				Int("tokens", len(tokens)).
+				Msg("no stored token belongs to the bot's own account, set botUserId to resume one")
+		}
+		return nil
+	}
+
+	b.helix.SetUserAccessToken(resume.AccessToken)
+	b.helix.SetRefreshToken(resume.RefreshToken)
+	b.transport.SetUserID(resume.UserID)
+
+	b.logger.Info().
+		Str("user_id", resume.UserID).
+		Str("login", resume.Login).
+		Msg("resumed with stored credentials")
+	return nil
+}
@@ -0,0 +1,201 @@
+package twitchgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Etwodev/twitchgo/pkg/config"
+	"github.com/Etwodev/twitchgo/pkg/log"
+)
+
+func TestResumeTokens(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	token := func(userID string, bot bool, age time.Duration) Token {
+		return Token{UserID: userID, AccessToken: userID + "-access", RefreshToken: userID + "-refresh", ObtainedAt: now.Add(-age), Bot: bot}
+	}
+
+	tests := []struct {
+		name      string
+		botUserID string
+		tokens    []Token
+		want      string
+	}{
+		{
+			name:   "bot account over a more recently refreshed broadcaster",
+			tokens: []Token{token("bot", true, time.Hour), token("broadcaster", false, time.Minute)},
+			want:   "bot",
+		},
+		{
+			name:      "configured botUserId",
+			botUserID: "broadcaster",
+			tokens:    []Token{token("bot", true, time.Hour), token("broadcaster", false, time.Minute)},
+			want:      "broadcaster",
+		},
+		{
+			name:   "single token stored before the bot account was recorded",
+			tokens: []Token{token("bot", false, time.Hour)},
+			want:   "bot",
+		},
+		{
+			name:   "several tokens without the bot account recorded",
+			tokens: []Token{token("bot", false, time.Hour), token("broadcaster", false, time.Minute)},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.BotUserID = tt.botUserID
+			b := newTestBot(t, nil, WithConfig(cfg))
+
+			for _, token := range tt.tokens {
+				if err := b.tokens.Save(context.Background(), token); err != nil {
+					t.Fatalf("Save: %v", err)
+				}
+			}
+
+			if err := b.resumeTokens(context.Background()); err != nil {
+				t.Fatalf("resumeTokens: %v", err)
+			}
+			if got := b.transport.UserID(); got != tt.want {
+				t.Errorf("resumed %q, want %q", got, tt.want)
+			}
+			if tt.want != "" && b.helix.GetUserAccessToken() != tt.want+"-access" {
+				t.Errorf("access token = %q, want %q", b.helix.GetUserAccessToken(), tt.want+"-access")
+			}
+		})
+	}
+}
+
+// testTokenKey returns a 32 byte key filled with b.
+func testTokenKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+// readTokenFile returns the decoded envelope of the token file at path.
+func readTokenFile(t *testing.T, path string) tokenFile {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading token file: %v", err)
+	}
+	var file tokenFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("decoding token file: %v", err)
+	}
+	return file
+}
+
+func TestFileTokenStoreEncryptsTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	store, err := NewFileTokenStore(path, testTokenKey(1))
+	if err != nil {
+		t.Fatalf("NewFileTokenStore: %v", err)
+	}
+
+	token := Token{UserID: "1234", AccessToken: "secret-access", RefreshToken: "secret-refresh", Scopes: []string{"chat:read"}}
+	if err := store.Save(context.Background(), token); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading token file: %v", err)
+	}
+	for _, secret := range []string{"secret-access", "secret-refresh"} {
+		if bytes.Contains(data, []byte(secret)) {
+			t.Errorf("token file contains %q in plaintext", secret)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("token file mode = %v, want 0600", mode)
+	}
+
+	reopened, err := NewFileTokenStore(path, testTokenKey(1))
+	if err != nil {
+		t.Fatalf("NewFileTokenStore: %v", err)
+	}
+	loaded, err := reopened.Load(context.Background(), "1234")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.AccessToken != token.AccessToken || loaded.RefreshToken != token.RefreshToken || len(loaded.Scopes) != 1 {
+		t.Errorf("loaded %+v, want %+v", loaded, token)
+	}
+}
+
+func TestFileTokenStoreKeyRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	oldKey, newKey := testTokenKey(1), testTokenKey(2)
+
+	old, err := NewFileTokenStore(path, oldKey)
+	if err != nil {
+		t.Fatalf("NewFileTokenStore: %v", err)
+	}
+	if err := old.Save(context.Background(), Token{UserID: "1234", AccessToken: "old-access"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	oldID := readTokenFile(t, path).KeyID
+
+	rotated, err := NewFileTokenStore(path, newKey, oldKey)
+	if err != nil {
+		t.Fatalf("NewFileTokenStore: %v", err)
+	}
+	if token, err := rotated.Load(context.Background(), "1234"); err != nil || token.AccessToken != "old-access" {
+		t.Fatalf("Load after rotation = %+v, %v, want the token written with the old key", token, err)
+	}
+
+	if err := rotated.Save(context.Background(), Token{UserID: "5678", AccessToken: "new-access"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if id := readTokenFile(t, path).KeyID; id == oldID {
+		t.Errorf("token file still encrypted with the old key %q after saving", id)
+	}
+
+	current, err := NewFileTokenStore(path, newKey)
+	if err != nil {
+		t.Fatalf("NewFileTokenStore: %v", err)
+	}
+	tokens, err := current.List(context.Background())
+	if err != nil {
+		t.Fatalf("List with only the new key: %v", err)
+	}
+	if len(tokens) != 2 {
+		t.Errorf("listed %d tokens, want both re-encrypted with the new key", len(tokens))
+	}
+
+	unknown, err := NewFileTokenStore(path, testTokenKey(3))
+	if err != nil {
+		t.Fatalf("NewFileTokenStore: %v", err)
+	}
+	if _, err := unknown.Load(context.Background(), "1234"); err == nil || !strings.Contains(err.Error(), "unknown key") {
+		t.Errorf("Load with an unknown key: %v, want an unknown key error", err)
+	}
+}
+
+func TestTokenFileRequiresKey(t *testing.T) {
+	cfg := config.Default()
+	cfg.TokenFile = filepath.Join(t.TempDir(), "tokens.json")
+
+	_, err := NewWithOptions(nil,
+		WithConfig(cfg),
+		WithLogger(&log.NoOpLogger{}),
+		WithSecretSource(func(name string) string { return "" }),
+	)
+	if err == nil || !strings.Contains(err.Error(), "no token encryption key") {
+		t.Errorf("NewWithOptions: %v, want the missing key reported", err)
+	}
+}
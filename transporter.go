@@ -2,9 +2,15 @@ package twitchgo
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/Etwodev/twitchgo/pkg/log"
 	"github.com/nicklaw5/helix/v2"
 )
 
@@ -12,6 +18,27 @@ type HelixRefreshTransport struct {
 	Base   http.RoundTripper
 	Client *helix.Client
 	Event  EventEngine
+	Store  TokenStore // if set, refreshed tokens are saved for the current user
+
+	app    *appTokens // if set, requests rejected with the app access token renew it
+	mu     sync.Mutex
+	userID string
+	now    func() time.Time
+	logger log.Logger
+}
+
+// SetUserID sets the user whose tokens the client holds, so refreshed tokens are saved for them.
+func (t *HelixRefreshTransport) SetUserID(userID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.userID = userID
+}
+
+// UserID returns the user whose tokens the client holds.
+func (t *HelixRefreshTransport) UserID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.userID
 }
 
 func (t *HelixRefreshTransport) RoundTrip(r *http.Request) (*http.Response, error) {
@@ -29,6 +56,21 @@ func (t *HelixRefreshTransport) RoundTrip(r *http.Request) (*http.Response, erro
 		return resp, err
 	}
 
+	// a rejected token request must not itself trigger a refresh
+	if r.URL.Host == "id.twitch.tv" {
+		return resp, err
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if t.app != nil && t.app.issued(token) {
+		accessToken, renewErr := t.app.renew(r.Context(), token)
+		if renewErr != nil {
+			resp.Body.Close()
+			return nil, renewErr
+		}
+		return t.retry(rt, r, resp, accessToken)
+	}
+
 	refresh := t.Client.GetRefreshToken()
 	if refresh == "" {
 		return resp, err
@@ -36,20 +78,89 @@ func (t *HelixRefreshTransport) RoundTrip(r *http.Request) (*http.Response, erro
 
 	newTokens, refreshErr := t.Client.RefreshUserAccessToken(refresh)
 	if refreshErr != nil {
-		return resp, refreshErr
+		resp.Body.Close()
+		return nil, refreshErr
+	}
+
+	// helix reports a rejected refresh in the response rather than as an error, so keep
+	// the current tokens and hand back the original 401
+	if newTokens.StatusCode != http.StatusOK || newTokens.ErrorMessage != "" || newTokens.Data.AccessToken == "" {
+		if t.logger != nil {
+			t.logger.Warn().
+				Int("status", newTokens.StatusCode).
+				Str("error", newTokens.ErrorMessage).
+				Str("user_id", t.UserID()).
+				Msg("failed to refresh user access token")
+		}
+		return resp, nil
 	}
 
 	t.Client.SetUserAccessToken(newTokens.Data.AccessToken)
 	t.Client.SetRefreshToken(newTokens.Data.RefreshToken)
+	t.save(r.Context(), newTokens.Data)
 	t.Event.OnClientRefresh(r.Context(), t.Client)
 
+	return t.retry(rt, r, resp, newTokens.Data.AccessToken)
+}
+
+// retry discards the rejected response and sends r again with accessToken.
+func (t *HelixRefreshTransport) retry(rt http.RoundTripper, r *http.Request, rejected *http.Response, accessToken string) (*http.Response, error) {
+	rejected.Body.Close()
+
 	retryReq := cloneRequest(r)
+	retryReq.Header.Set("Authorization", "Bearer "+accessToken)
 	return rt.RoundTrip(retryReq)
 }
 
+// save stores refreshed credentials for the current user, keeping their other details.
+func (t *HelixRefreshTransport) save(ctx context.Context, creds helix.AccessCredentials) {
+	userID := t.UserID()
+	if t.Store == nil || userID == "" {
+		return
+	}
+
+	now := time.Now
+	if t.now != nil {
+		now = t.now
+	}
+
+	token, err := t.Store.Load(ctx, userID)
+	if err != nil && !errors.Is(err, ErrTokenNotFound) {
+		t.logError(err, userID)
+		return
+	}
+
+	token.UserID = userID
+	token.AccessToken = creds.AccessToken
+	token.RefreshToken = creds.RefreshToken
+	token.ObtainedAt = now()
+	token.ExpiresAt = token.ObtainedAt.Add(time.Duration(creds.ExpiresIn) * time.Second)
+	if len(creds.Scopes) > 0 {
+		token.Scopes = creds.Scopes
+	}
+
+	if err := t.Store.Save(ctx, token); err != nil {
+		t.logError(err, userID)
+	}
+}
+
+func (t *HelixRefreshTransport) logError(err error, userID string) {
+	if t.logger != nil {
+		t.logger.Error().Err(err).Str("user_id", userID).Msg("failed to save refreshed token")
+	}
+}
+
 func cloneRequest(r *http.Request) *http.Request {
 	c := r.Clone(r.Context())
 
+	// the body has been read by the first attempt, so replay it if possible
+	if r.GetBody != nil {
+		if body, err := r.GetBody(); err == nil {
+			c.Body = body
+			return c
+		}
+	}
+
 	if r.Body != nil {
 		data, _ := io.ReadAll(r.Body)
 		r.Body.Close()
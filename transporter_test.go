@@ -0,0 +1,210 @@
+package twitchgo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nicklaw5/helix/v2"
+)
+
+// roundTripFunc is an http.RoundTripper calling a function.
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// jsonResponse returns a response to r with status and body.
+func jsonResponse(r *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    r,
+	}
+}
+
+type refreshEngine struct {
+	BaseEngine
+	refreshed atomic.Int32
+}
+
+func (e *refreshEngine) OnClientRefresh(ctx context.Context, api *helix.Client) {
+	e.refreshed.Add(1)
+}
+
+// loggedInBot returns a bot whose own client acts as user 1234, calling Twitch through base.
+func loggedInBot(t *testing.T, engine EventEngine, base http.RoundTripper) *Bot {
+	t.Helper()
+
+	b := newTestBot(t, engine, WithTransport(base))
+	token := Token{UserID: "1234", Login: "bot", AccessToken: "old-access", RefreshToken: "old-refresh"}
+	if err := b.tokens.Save(context.Background(), token); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := b.login(token); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	return b
+}
+
+// getUsers sends a Helix request with the user access token through the bot's transport.
+func getUsers(t *testing.T, b *Bot) (*http.Response, error) {
+	t.Helper()
+
+	r, err := http.NewRequest(http.MethodGet, "https://api.twitch.tv/helix/users", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	r.Header.Set("Authorization", "Bearer "+b.helix.GetUserAccessToken())
+	return b.transport.RoundTrip(r)
+}
+
+func TestRefreshRejectedKeepsTokens(t *testing.T) {
+	var refreshes atomic.Int32
+	engine := &refreshEngine{}
+	b := loggedInBot(t, engine, roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Host == "id.twitch.tv" {
+			refreshes.Add(1)
+			return jsonResponse(r, http.StatusBadRequest, `{"status":400,"message":"Invalid refresh token"}`), nil
+		}
+		return jsonResponse(r, http.StatusUnauthorized, `{"status":401,"message":"Invalid OAuth token"}`), nil
+	}))
+
+	resp, err := getUsers(t, b)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want the original %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+	if body, _ := io.ReadAll(resp.Body); !strings.Contains(string(body), "Invalid OAuth token") {
+		t.Errorf("body = %s, want the original response", body)
+	}
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("refresh requests = %d, want 1", n)
+	}
+	if n := engine.refreshed.Load(); n != 0 {
+		t.Errorf("OnClientRefresh called %d times, want 0", n)
+	}
+
+	if access, refresh := b.helix.GetUserAccessToken(), b.helix.GetRefreshToken(); access != "old-access" || refresh != "old-refresh" {
+		t.Errorf("client tokens = %q, %q, want them unchanged", access, refresh)
+	}
+	stored, err := b.tokens.Load(context.Background(), "1234")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if stored.AccessToken != "old-access" || stored.RefreshToken != "old-refresh" {
+		t.Errorf("stored token = %+v, want it unchanged", stored)
+	}
+}
+
+func TestRefreshRetriesWithNewToken(t *testing.T) {
+	engine := &refreshEngine{}
+	b := loggedInBot(t, engine, roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Host == "id.twitch.tv" {
+			return jsonResponse(r, http.StatusOK, `{"access_token":"new-access","refresh_token":"new-refresh","expires_in":3600,"scope":["user:read:chat"]}`), nil
+		}
+		if r.Header.Get("Authorization") != "Bearer new-access" {
+			return jsonResponse(r, http.StatusUnauthorized, `{"status":401,"message":"Invalid OAuth token"}`), nil
+		}
+		return jsonResponse(r, http.StatusOK, `{"data":[]}`), nil
+	}))
+
+	resp, err := getUsers(t, b)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if n := engine.refreshed.Load(); n != 1 {
+		t.Errorf("OnClientRefresh called %d times, want 1", n)
+	}
+	stored, err := b.tokens.Load(context.Background(), "1234")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if stored.AccessToken != "new-access" || stored.RefreshToken != "new-refresh" || stored.Login != "bot" {
+		t.Errorf("stored token = %+v, want the new tokens with the login kept", stored)
+	}
+}
+
+// trackedBody is a response body recording whether it was closed.
+type trackedBody struct {
+	io.Reader
+	closed atomic.Bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed.Store(true)
+	return nil
+}
+
+func TestFailedRenewalReturnsOnlyTheError(t *testing.T) {
+	tests := []struct {
+		name      string
+		bearer    func(b *Bot) string
+		tokenFail func(r *http.Request) (*http.Response, error)
+	}{
+		{
+			name:   "app token renewal rejected",
+			bearer: func(b *Bot) string { return b.appTokens.current() },
+			tokenFail: func(r *http.Request) (*http.Response, error) {
+				return jsonResponse(r, http.StatusBadRequest, `{"status":400,"message":"invalid client secret"}`), nil
+			},
+		},
+		{
+			name:   "user token refresh unreachable",
+			bearer: func(b *Bot) string { return b.helix.GetUserAccessToken() },
+			tokenFail: func(r *http.Request) (*http.Response, error) {
+				return nil, errors.New("connection refused")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rejected := &trackedBody{Reader: strings.NewReader(`{"status":401,"message":"Invalid OAuth token"}`)}
+			b := loggedInBot(t, nil, roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				if r.URL.Host == "id.twitch.tv" {
+					return tt.tokenFail(r)
+				}
+				resp := jsonResponse(r, http.StatusUnauthorized, "")
+				resp.Body = rejected
+				return resp, nil
+			}))
+
+			b.appTokens.mu.Lock()
+			b.appTokens.token = "app-access"
+			b.appTokens.mu.Unlock()
+
+			r, err := http.NewRequest(http.MethodGet, "https://api.twitch.tv/helix/users", nil)
+			if err != nil {
+				t.Fatalf("NewRequest: %v", err)
+			}
+			r.Header.Set("Authorization", "Bearer "+tt.bearer(b))
+
+			resp, err := b.transport.RoundTrip(r)
+			if err == nil {
+				t.Error("RoundTrip succeeded, want the failure reported")
+			}
+			if resp != nil {
+				t.Errorf("RoundTrip returned a response alongside its error: %d", resp.StatusCode)
+			}
+			if !rejected.closed.Load() {
+				t.Error("rejected response body left open")
+			}
+		})
+	}
+}
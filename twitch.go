@@ -2,10 +2,14 @@ package twitchgo
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/Etwodev/twitchgo/pkg/config"
@@ -18,14 +22,34 @@ import (
 
 // configuration, middleware, routers, and structured logging.
 type Bot struct {
-	logger      log.Logger
-	engine      EventEngine
-	cache       *dedupeCache
-	instance    *http.Server
-	helix       *helix.Client
-	middlewares []middleware.Middleware
-	routers     []router.Router
-	idle        chan struct{}
+	config        *config.Config
+	secrets       SecretSource
+	clock         Clock
+	client        *http.Client
+	transport     *HelixRefreshTransport
+	tokens        TokenStore
+	users         *userClients
+	logger        log.Logger
+	engine        *engineSet
+	cache         *dedupeCache
+	registry      *registry
+	revocations   *revocations
+	instance      *http.Server
+	helix         *helix.Client
+	app           *helix.Client
+	appTokens     *appTokens
+	websocket     *WebsocketClient
+	subscriptions *SubscriptionManager
+	conduits      *ConduitManager
+	middlewares   []middleware.Middleware
+	routers       []router.Router
+	dispatcher    *dispatcher
+	mu            sync.Mutex
+	stopOnce      sync.Once
+	stopped       chan struct{}
+	stopErr       error
+	ctx           context.Context
+	cancel        context.CancelFunc
 }
 
 // LoadRouter appends one or more routers to the server's router list.
@@ -53,54 +77,217 @@ func (b *Bot) LoadMiddleware(middlewares []middleware.Middleware) {
 //
 // It will fatal exit if configuration loading failb.
 //
+// engine is loaded as a plugin named "Engine" with priority 0. It may be nil
+// if all engines are loaded with LoadPlugin. It will also fatal exit if engine has
+// an On* method whose signature does not match its handler interface.
+//
 // Example:
 //
 //	bot := twitchgo.New()
 func New(engine EventEngine) *Bot {
-	err := config.New()
+	b, err := NewWithOptions(engine)
 	if err != nil {
 		baseLogger := zerolog.New(os.Stdout).With().Timestamp().Str("Group", "twitchgo").Logger()
-		baseLogger.Fatal().Str("Function", "New").Err(err).Msg("Failed to load config")
+		baseLogger.Fatal().Str("Function", "New").Err(err).Msg("Failed to create bot")
 	}
+	return b
+}
 
-	level, err := zerolog.ParseLevel(config.LogLevel())
-	if err != nil {
-		level = zerolog.InfoLevel
+// NewWithOptions creates a new Bot instance configured by opts.
+//
+// Anything not set by an option falls back to the behaviour of New: the configuration
+// is loaded from ./twitchgo.config.json, secrets are read from the environment,
+// tokens are kept in memory unless a tokenFile is configured, and Twitch is called through
+// http.DefaultTransport. A bot given its configuration with WithConfig does not
+// read the configuration file, and several may run in one process.
+//
+// The bot resumes with the stored token of the configured botUserId, or else of the
+// user who last logged in as its own account, if any.
+//
+// Example:
+//
+//	cfg := config.Default()
+//	cfg.ClientID = "abc123"
+//	bot, err := twitchgo.NewWithOptions(engine,
+//	    twitchgo.WithConfig(cfg),
+//	    twitchgo.WithSecretSource(secrets.Lookup),
+//	)
+func NewWithOptions(engine EventEngine, opts ...Option) (*Bot, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
 	}
-	zerolog.SetGlobalLevel(level)
 
-	format := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: "2006-01-02T15:04:05"}
-	baseLogger := zerolog.New(format).With().Timestamp().Str("Group", "twitchgo").Logger()
-	logger := log.NewZeroLogger(baseLogger)
+	if o.config == nil {
+		if err := config.New(); err != nil {
+			return nil, fmt.Errorf("NewWithOptions: failed loading config: %w", err)
+		}
+		cfg := config.Get()
+		o.config = &cfg
+	}
+	cfg := o.config
 
-	transport := &HelixRefreshTransport{
-		Base:  http.DefaultTransport,
-		Event: engine,
+	if cfg.EventOverflow != OverflowBlock && cfg.EventOverflow != OverflowDrop {
+		return nil, fmt.Errorf("NewWithOptions: eventOverflow is %q, want %q or %q", cfg.EventOverflow, OverflowBlock, OverflowDrop)
 	}
 
-	httpClient := &http.Client{
-		Transport: transport,
+	logger := o.logger
+	if logger == nil {
+		level, err := zerolog.ParseLevel(cfg.LogLevel)
+		if err != nil {
+			level = zerolog.InfoLevel
+		}
+
+		format := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: "2006-01-02T15:04:05"}
+		baseLogger := zerolog.New(format).Level(level).With().Timestamp().Str("Group", "twitchgo").Logger()
+		logger = log.NewZeroLogger(baseLogger)
+	}
+
+	engines := newEngineSet(cfg.Experimental)
+	if engine != nil {
+		if err := checkEngine(engine); err != nil {
+			return nil, fmt.Errorf("NewWithOptions: invalid engine: %w", err)
+		}
+		engines.add(NewPlugin(engine, "Engine", 0, true, false))
+	}
+
+	// plain is used for requests that need no Twitch token refresh, such as the OAuth flow
+	plain := &http.Client{Timeout: 30 * time.Second}
+	if o.client != nil {
+		c := *o.client
+		plain = &c
+	}
+	if o.transport != nil {
+		plain.Transport = o.transport
+	}
+
+	base := plain.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	tokens := o.tokens
+	if tokens == nil {
+		if cfg.TokenFile != "" {
+			keys, err := tokenKeys(cfg, o.secrets)
+			if err != nil {
+				return nil, fmt.Errorf("NewWithOptions: %w", err)
+			}
+			if len(keys) == 0 {
+				return nil, errors.New("NewWithOptions: tokenFile is set but no token encryption key was given, " +
+					"set TOKEN_KEYS or tokenKeyFile, or clear tokenFile to keep tokens in memory")
+			}
+
+			store, err := NewFileTokenStore(cfg.TokenFile, keys...)
+			if err != nil {
+				return nil, fmt.Errorf("NewWithOptions: %w", err)
+			}
+			tokens = store
+		} else {
+			tokens = NewMemoryTokenStore()
+		}
 	}
 
-	opts := &helix.Options{
-		HTTPClient:   httpClient,
-		ClientID:     config.ClientID(),
-		ClientSecret: os.Getenv("CLIENT_SECRET"),
+	transport := &HelixRefreshTransport{
+		Base:   base,
+		Event:  engines,
+		Store:  tokens,
+		now:    o.clock.Now,
+		logger: logger,
 	}
 
-	client, err := helix.NewClient(opts)
+	httpClient := *plain
+	httpClient.Transport = transport
+
+	client, err := helix.NewClient(&helix.Options{
+		HTTPClient:   &httpClient,
+		ClientID:     cfg.ClientID,
+		ClientSecret: o.secrets("CLIENT_SECRET"),
+	})
 	if err != nil {
-		logger.Fatal().Str("Function", "New").Err(err).Msg("Failed to setup helix client")
+		return nil, fmt.Errorf("NewWithOptions: failed creating helix client: %w", err)
 	}
 
 	transport.Client = client
 
-	return &Bot{
-		engine: engine,
+	// app is only ever authorized with the app access token
+	appTransport := &HelixRefreshTransport{
+		Base:   base,
+		Event:  engines,
+		now:    o.clock.Now,
 		logger: logger,
-		helix:  client,
-		cache:  newDedupeCache(5 * time.Minute),
 	}
+
+	appHTTPClient := *plain
+	appHTTPClient.Transport = appTransport
+
+	app, err := helix.NewClient(&helix.Options{
+		HTTPClient:   &appHTTPClient,
+		ClientID:     cfg.ClientID,
+		ClientSecret: o.secrets("CLIENT_SECRET"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("NewWithOptions: failed creating helix client: %w", err)
+	}
+
+	appTransport.Client = app
+
+	b := &Bot{
+		config:      cfg,
+		secrets:     o.secrets,
+		clock:       o.clock,
+		client:      plain,
+		transport:   transport,
+		tokens:      tokens,
+		users:       newUserClients(),
+		engine:      engines,
+		logger:      logger,
+		helix:       client,
+		app:         app,
+		registry:    newRegistry(),
+		revocations: newRevocations(),
+		stopped:     make(chan struct{}),
+	}
+	b.appTokens = newAppTokens(b, client, app)
+	transport.app = b.appTokens
+	appTransport.app = b.appTokens
+	b.cache = newDedupeCache(5*time.Minute, b.now)
+	b.ctx, b.cancel = context.WithCancel(context.Background())
+	b.dispatcher = newDispatcher(b, cfg.EventWorkers, cfg.EventQueueSize, cfg.EventOverflow)
+	b.websocket = NewWebsocketClient(b, cfg.WebsocketURL)
+	b.subscriptions = NewSubscriptionManager(b, client, cfg.CallbackURL)
+	b.subscriptions.app = app
+	b.conduits = NewConduitManager(b, app, "")
+	b.conduits.client = &appHTTPClient
+	if cfg.ConduitID != "" && Method(cfg.Transport) == Websocket {
+		b.conduits.BindWebsocketShard(cfg.ConduitID, cfg.ConduitShard)
+	}
+	b.websocket.OnSession(func(ctx context.Context, session WebsocketSession) {
+		if len(b.subscriptions.Desired()) == 0 {
+			return
+		}
+		go func() {
+			if err := b.subscriptions.Reconcile(ctx); err != nil {
+				b.logger.Error().Err(err).Msg("failed to reconcile subscriptions")
+			}
+		}()
+	})
+	registerEngine(b)
+
+	if err := b.resumeTokens(context.Background()); err != nil {
+		return nil, fmt.Errorf("NewWithOptions: failed loading stored tokens: %w", err)
+	}
+
+	return b, nil
+}
+
+// Config returns a copy of the configuration used by the bot.
+//
+// Example:
+//
+//	port := bot.Config().Port
+func (b *Bot) Config() config.Config {
+	return *b.config
 }
 
 // Logger returns the logger instance used by the bot.
@@ -123,66 +310,233 @@ func (b *Bot) Helix() *helix.Client {
 	return b.helix
 }
 
-// Start launches the HTTP server, applying configured middleware and routers,
-// and listens for termination signals for graceful shutdown.
+// Websocket returns the EventSub websocket client used by the bot.
 //
-// It blocks until the server is shut down.
+// It is only connected when the configured transport is "websocket".
 //
 // Example:
 //
-//	bot.Start()
-func (b *Bot) Start() {
-	b.instance = &http.Server{
-		Addr:           fmt.Sprintf("%s:%s", config.Address(), config.Port()),
+//	bot.Websocket().OnSession(func(ctx context.Context, s twitchgo.WebsocketSession) {
+//	    // create subscriptions using s.ID
+//	})
+func (b *Bot) Websocket() *WebsocketClient {
+	return b.websocket
+}
+
+// Subscriptions returns the subscription manager used by the bot.
+//
+// Subscriptions declared before Start, typically in OnBotStart, are reconciled
+// every syncInterval seconds and whenever a new websocket session is established.
+//
+// Example:
+//
+//	bot.Subscriptions().Declare(twitchgo.SubscriptionSpec{
+//	    Type:      "stream.online",
+//	    Version:   "1",
+//	    Condition: helix.EventSubCondition{BroadcasterUserID: "1234"},
+//	    Transport: twitchgo.Transport{Method: twitchgo.Webhook},
+//	})
+func (b *Bot) Subscriptions() *SubscriptionManager {
+	return b.subscriptions
+}
+
+// Conduits returns the conduit manager used by the bot.
+//
+// When conduitId is configured, the conduitShard shard is assigned to the bot's
+// webhook callback or websocket session on start, and reassigned on reconnect.
+//
+// Example:
+//
+//	conduit, err := bot.Conduits().CreateConduit(ctx, 1)
+func (b *Bot) Conduits() *ConduitManager {
+	return b.conduits
+}
+
+// Start runs the bot until it receives SIGINT or SIGTERM.
+//
+// It blocks until the bot has shut down, returning any error from Run.
+//
+// Example:
+//
+//	if err := bot.Start(); err != nil {
+//	    logger.Error().Err(err).Msg("bot stopped")
+//	}
+func (b *Bot) Start() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return b.Run(ctx)
+}
+
+// Run launches the HTTP server, applying configured middleware and routers, and
+// runs the bot until ctx is cancelled or Shutdown is called.
+//
+// It blocks until the bot has shut down. Errors listening, serving or shutting
+// down are returned rather than exiting.
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	defer cancel()
+//	if err := bot.Run(ctx); err != nil {
+//	    return err
+//	}
+func (b *Bot) Run(ctx context.Context) error {
+	instance := &http.Server{
+		Addr:           fmt.Sprintf("%s:%s", b.config.Address, b.config.Port),
 		Handler:        b.handler(),
-		ReadTimeout:    time.Duration(config.ReadTimeout()) * time.Second,
-		WriteTimeout:   time.Duration(config.WriteTimeout()) * time.Second,
-		IdleTimeout:    time.Duration(config.IdleTimeout()) * time.Second,
-		MaxHeaderBytes: config.MaxHeaderBytes(),
+		ReadTimeout:    time.Duration(b.config.ReadTimeout) * time.Second,
+		WriteTimeout:   time.Duration(b.config.WriteTimeout) * time.Second,
+		IdleTimeout:    time.Duration(b.config.IdleTimeout) * time.Second,
+		MaxHeaderBytes: b.config.MaxHeaderBytes,
 	}
 
+	b.mu.Lock()
+	b.instance = instance
+	b.mu.Unlock()
+
 	b.logger.Debug().
-		Str("Port", config.Port()).
-		Str("Address", config.Address()).
-		Bool("Experimental", config.Experimental()).
+		Str("Port", b.config.Port).
+		Str("Address", b.config.Address).
+		Bool("Experimental", b.config.Experimental).
 		Msg("Server starting")
 
-	// NOTE: Investigate what sort of context should be used here
-	b.engine.OnBotStart(context.Background(), b.helix)
+	ln, err := net.Listen("tcp", instance.Addr)
+	if err != nil {
+		// nothing has started, so the bot is stopped without calling OnBotStop
+		b.stopOnce.Do(func() {
+			b.cancel()
+			_ = b.dispatcher.drain(context.Background())
+			close(b.stopped)
+		})
+		return fmt.Errorf("Run: failed listening: %w", err)
+	}
+
+	b.appTokens.start(b.ctx)
+	go b.validateTokens(b.ctx)
+	b.engine.OnBotStart(ctx, b.helix)
 
-	b.idle = make(chan struct{})
-	go func() {
-		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt)
-		<-sigint
+	if Method(b.config.Transport) == Websocket {
+		go func() {
+			if err := b.websocket.Run(b.ctx); err != nil {
+				b.logger.Error().Err(err).Msg("EventSub websocket stopped")
+			}
+		}()
+	}
 
-		timeout := time.Duration(config.ShutdownTimeout()) * time.Second
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
+	// the listener accepts connections from here, so webhook verification challenges
+	// sent for subscriptions created below can be answered
+	go b.ready(b.ctx)
 
-		if err := b.instance.Shutdown(ctx); err != nil {
-			b.logger.Warn().Str("Function", "Shutdown").Err(err).Msg("Server shutdown failed!")
+	served := make(chan error, 1)
+	go func() {
+		if b.config.EnableTLS {
+			b.logger.Info().Msg("Starting HTTPS server")
+			served <- instance.ServeTLS(ln, b.config.TLSCertFile, b.config.TLSKeyFile)
+		} else {
+			b.logger.Info().Msg("Starting HTTP server")
+			served <- instance.Serve(ln)
 		}
-		close(b.idle)
 	}()
 
-	if config.EnableTLS() {
-		b.logger.Info().Msg("Starting HTTPS server")
-		if err := b.instance.ListenAndServeTLS(config.TLSCertFile(), config.TLSKeyFile()); err != nil && err != http.ErrServerClosed {
-			b.logger.Fatal().Err(err).Msg("HTTPS server failed")
-		}
-	} else {
-		b.logger.Info().Msg("Starting HTTP server")
-		if err := b.instance.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			b.logger.Fatal().Err(err).Msg("HTTP server failed")
+	var serveErr error
+	select {
+	case err := <-served:
+		if !errors.Is(err, http.ErrServerClosed) {
+			serveErr = fmt.Errorf("Run: server failed: %w", err)
 		}
+	case <-ctx.Done():
 	}
 
-	<-b.idle
+	// the deadline only applies to a shutdown started here; one started by a
+	// call to Shutdown is waited for regardless
+	timeout := time.Duration(b.config.ShutdownTimeout) * time.Second
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	b.shutdown(shutdownCtx)
+	<-b.stopped
 
 	b.logger.Debug().
-		Str("Port", config.Port()).
-		Str("Address", config.Address()).
-		Bool("Experimental", config.Experimental()).
+		Str("Port", b.config.Port).
+		Str("Address", b.config.Address).
+		Bool("Experimental", b.config.Experimental).
 		Msg("Server stopped")
+
+	return errors.Join(serveErr, b.stopErr)
+}
+
+// Shutdown gracefully stops the bot, calling OnBotStop, stopping the server and
+// draining queued events before ctx expires. It may be called from any goroutine,
+// and only the first call shuts the bot down; later calls wait for it to finish.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+//	defer cancel()
+//	if err := bot.Shutdown(ctx); err != nil {
+//	    logger.Warn().Err(err).Msg("shutdown failed")
+//	}
+func (b *Bot) Shutdown(ctx context.Context) error {
+	b.shutdown(ctx)
+
+	select {
+	case <-b.stopped:
+		return b.stopErr
+	case <-ctx.Done():
+		return fmt.Errorf("Shutdown: %w", ctx.Err())
+	}
+}
+
+// shutdown starts shutting the bot down with ctx, unless it already has been.
+func (b *Bot) shutdown(ctx context.Context) {
+	b.stopOnce.Do(func() {
+		go func() {
+			defer close(b.stopped)
+
+			b.engine.OnBotStop(ctx, b.helix)
+
+			var errs []error
+
+			b.mu.Lock()
+			instance := b.instance
+			b.mu.Unlock()
+
+			if instance != nil {
+				if err := instance.Shutdown(ctx); err != nil {
+					b.logger.Warn().Str("Function", "Shutdown").Err(err).Msg("Server shutdown failed!")
+					errs = append(errs, fmt.Errorf("Shutdown: failed stopping server: %w", err))
+				}
+			}
+			if err := b.dispatcher.drain(ctx); err != nil {
+				b.logger.Warn().Str("Function", "Shutdown").Err(err).Msg("Event queue drain failed!")
+				errs = append(errs, fmt.Errorf("Shutdown: failed draining events: %w", err))
+			}
+
+			b.cancel()
+			b.stopErr = errors.Join(errs...)
+		}()
+	})
+}
+
+// ready runs once the server is accepting connections, assigning the webhook conduit shard,
+// starting subscription reconciliation and calling OnReady.
+func (b *Bot) ready(ctx context.Context) {
+	if b.config.ConduitID != "" && Method(b.config.Transport) == Webhook {
+		go func() {
+			if err := b.conduits.AssignWebhookShard(ctx, b.config.ConduitID, b.config.ConduitShard, b.config.CallbackURL); err != nil {
+				b.logger.Error().Err(err).Str("conduit_id", b.config.ConduitID).Msg("failed to assign conduit shard")
+			}
+		}()
+	}
+
+	if len(b.subscriptions.Desired()) > 0 {
+		interval := time.Duration(b.config.SyncInterval) * time.Second
+		go b.subscriptions.Run(ctx, interval)
+	}
+
+	b.logger.Info().
+		Str("Port", b.config.Port).
+		Str("Address", b.config.Address).
+		Msg("Server ready")
+	b.engine.OnReady(ctx, b.helix)
 }
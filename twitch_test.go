@@ -0,0 +1,178 @@
+package twitchgo
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Etwodev/twitchgo/pkg/config"
+	"github.com/nicklaw5/helix/v2"
+)
+
+type lifecycleEngine struct {
+	BaseEngine
+	started chan struct{}
+	stopped chan struct{}
+}
+
+func newLifecycleEngine() *lifecycleEngine {
+	return &lifecycleEngine{started: make(chan struct{}, 1), stopped: make(chan struct{}, 1)}
+}
+
+func (e *lifecycleEngine) OnBotStart(ctx context.Context, api *helix.Client) {
+	e.started <- struct{}{}
+}
+
+func (e *lifecycleEngine) OnBotStop(ctx context.Context, api *helix.Client) {
+	e.stopped <- struct{}{}
+}
+
+// runnableBot returns a bot listening on address and port, answering app access token
+// requests without calling Twitch.
+func runnableBot(t *testing.T, engine EventEngine, address, port string) *Bot {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Address = address
+	cfg.Port = port
+	cfg.ShutdownTimeout = 5
+
+	return newTestBot(t, engine, WithConfig(cfg), WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return jsonResponse(r, http.StatusOK, `{"access_token":"app-token","expires_in":3600}`), nil
+	})))
+}
+
+func TestRunStopsWhenContextDone(t *testing.T) {
+	engine := newLifecycleEngine()
+	b := runnableBot(t, engine, "127.0.0.1", "0")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- b.Run(ctx) }()
+
+	receive(t, engine.started, "OnBotStart")
+	cancel()
+
+	if err := receive(t, done, "Run to return"); err != nil {
+		t.Errorf("Run: %v, want nil after the context is cancelled", err)
+	}
+	receive(t, engine.stopped, "OnBotStop")
+	if b.ctx.Err() == nil {
+		t.Error("bot context still live after Run returned")
+	}
+}
+
+func TestRunReportsListenError(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer taken.Close()
+	_, port, _ := net.SplitHostPort(taken.Addr().String())
+
+	engine := newLifecycleEngine()
+	b := runnableBot(t, engine, "127.0.0.1", port)
+
+	err = b.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "failed listening") {
+		t.Fatalf("Run: %v, want the listen error", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := b.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown after a failed Run: %v, want nil", err)
+	}
+
+	select {
+	case <-engine.started:
+		t.Error("OnBotStart called although the bot never listened")
+	case <-engine.stopped:
+		t.Error("OnBotStop called although the bot never started")
+	default:
+	}
+	if b.ctx.Err() == nil {
+		t.Error("bot context still live after Run failed")
+	}
+}
+
+// freePort returns a port on 127.0.0.1 that nothing is listening on.
+func freePort(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer ln.Close()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	return port
+}
+
+// hookEngine dials addr from OnReady and OnBotStop, reporting whether the server was listening.
+type hookEngine struct {
+	BaseEngine
+	addr     string
+	ready    chan error
+	stopping chan error
+	deadline chan time.Duration
+}
+
+func (e *hookEngine) dial() error {
+	conn, err := net.DialTimeout("tcp", e.addr, time.Second)
+	if err == nil {
+		conn.Close()
+	}
+	return err
+}
+
+func (e *hookEngine) OnReady(ctx context.Context, api *helix.Client) {
+	e.ready <- e.dial()
+}
+
+func (e *hookEngine) OnBotStop(ctx context.Context, api *helix.Client) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		e.deadline <- 0
+	} else {
+		e.deadline <- time.Until(deadline)
+	}
+	e.stopping <- e.dial()
+}
+
+func TestLifecycleHooksSeeServer(t *testing.T) {
+	port := freePort(t)
+	engine := &hookEngine{
+		addr:     net.JoinHostPort("127.0.0.1", port),
+		ready:    make(chan error, 1),
+		stopping: make(chan error, 1),
+		deadline: make(chan time.Duration, 1),
+	}
+	b := runnableBot(t, engine, "127.0.0.1", port)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- b.Run(ctx) }()
+
+	if err := receive(t, engine.ready, "OnReady"); err != nil {
+		t.Errorf("OnReady called before the server accepted connections: %v", err)
+	}
+
+	cancel()
+	if remaining := receive(t, engine.deadline, "OnBotStop"); remaining <= 0 || remaining > 5*time.Second {
+		t.Errorf("OnBotStop context expires in %v, want within the 5s shutdownTimeout", remaining)
+	}
+	if err := receive(t, engine.stopping, "OnBotStop"); err != nil {
+		t.Errorf("OnBotStop called after the server stopped: %v", err)
+	}
+	if err := receive(t, done, "Run to return"); err != nil {
+		t.Errorf("Run: %v", err)
+	}
+
+	if err := engine.dial(); err == nil {
+		t.Error("server still accepting connections after Run returned")
+	}
+}
@@ -0,0 +1,199 @@
+package twitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/nicklaw5/helix/v2"
+)
+
+// errTokenInvalid is returned by validateToken when Twitch rejects the token.
+var errTokenInvalid = errors.New("token is invalid")
+
+// tokenValidation describes an access token, as returned by the Twitch validate endpoint.
+type tokenValidation struct {
+	ClientID  string   `json:"client_id"`
+	Login     string   `json:"login"`
+	Scopes    []string `json:"scopes"`
+	UserID    string   `json:"user_id"`
+	ExpiresIn int      `json:"expires_in"` // seconds until the token expires
+}
+
+// validateToken asks Twitch who an access token belongs to, returning errTokenInvalid
+// if it has expired or been revoked.
+func (b *Bot) validateToken(ctx context.Context, accessToken string) (tokenValidation, error) {
+	var info tokenValidation
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://id.twitch.tv/oauth2/validate", nil)
+	if err != nil {
+		return info, fmt.Errorf("validateToken: failed building request: %w", err)
+	}
+	req.Header.Set("Authorization", "OAuth "+accessToken)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return info, fmt.Errorf("validateToken: failed executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return info, errTokenInvalid
+	}
+	if resp.StatusCode != http.StatusOK {
+		return info, fmt.Errorf("validateToken: unexpected status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return info, fmt.Errorf("validateToken: failed decoding response: %w", err)
+	}
+	return info, nil
+}
+
+// userClients holds the helix clients acting as each user other than the one held
+// by the bot's own client, created on first use.
+type userClients struct {
+	mu      sync.Mutex
+	clients map[string]*helix.Client
+}
+
+func newUserClients() *userClients {
+	return &userClients{clients: make(map[string]*helix.Client)}
+}
+
+// HelixFor returns a Helix client acting as userID, using the token stored for them.
+//
+// Each user's client refreshes its own token, so any number of users, such as the bot
+// account and the broadcasters it acts for, may be logged in at once. The client of the
+// bot's own account is the one returned by Helix. It returns ErrTokenNotFound if the
+// user has not logged in.
+//
+// Example:
+//
+//	api, err := bot.HelixFor(broadcasterID)
+//	if err != nil {
+//	    return err
+//	}
+//	_, err = api.SendChatMessage(&helix.SendChatMessageParams{
+//	    BroadcasterID: broadcasterID,
+//	    SenderID:      broadcasterID,
+//	    Message:       "Stream starting!",
+//	})
+func (b *Bot) HelixFor(userID string) (*helix.Client, error) {
+	b.users.mu.Lock()
+	defer b.users.mu.Unlock()
+
+	if userID == b.transport.UserID() {
+		return b.helix, nil
+	}
+	if client, ok := b.users.clients[userID]; ok {
+		return client, nil
+	}
+
+	token, err := b.tokens.Load(context.Background(), userID)
+	if err != nil {
+		return nil, fmt.Errorf("HelixFor: %w", err)
+	}
+
+	client, err := b.newUserClient(token)
+	if err != nil {
+		return nil, fmt.Errorf("HelixFor: %w", err)
+	}
+	b.users.clients[userID] = client
+	return client, nil
+}
+
+// login makes a newly obtained token the one used to act as its user, returning the
+// client acting as them.
+//
+// The bot's own client takes the token if it belongs to the configured botUserId, or if
+// no botUserId is configured and the client holds no user yet.
+func (b *Bot) login(token Token) (*helix.Client, error) {
+	b.users.mu.Lock()
+	defer b.users.mu.Unlock()
+
+	current := b.transport.UserID()
+	if current == token.UserID || b.config.BotUserID == token.UserID || (b.config.BotUserID == "" && current == "") {
+		b.helix.SetUserAccessToken(token.AccessToken)
+		b.helix.SetRefreshToken(token.RefreshToken)
+		b.transport.SetUserID(token.UserID)
+		delete(b.users.clients, token.UserID)
+		return b.helix, nil
+	}
+
+	if client, ok := b.users.clients[token.UserID]; ok {
+		client.SetUserAccessToken(token.AccessToken)
+		client.SetRefreshToken(token.RefreshToken)
+		return client, nil
+	}
+
+	client, err := b.newUserClient(token)
+	if err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	b.users.clients[token.UserID] = client
+	return client, nil
+}
+
+// newUserClient creates a helix client acting as the user of token, refreshing
+// and saving its token independently of other clients.
+func (b *Bot) newUserClient(token Token) (*helix.Client, error) {
+	transport := &HelixRefreshTransport{
+		Base:   b.transport.Base,
+		Event:  b.engine,
+		Store:  b.tokens,
+		userID: token.UserID,
+		now:    b.now,
+		logger: b.logger,
+	}
+
+	httpClient := *b.client
+	httpClient.Transport = transport
+
+	client, err := helix.NewClient(&helix.Options{
+		HTTPClient:      &httpClient,
+		ClientID:        b.config.ClientID,
+		ClientSecret:    b.secret("CLIENT_SECRET"),
+		UserAccessToken: token.AccessToken,
+		RefreshToken:    token.RefreshToken,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed creating helix client: %w", err)
+	}
+
+	transport.Client = client
+	return client, nil
+}
+
+// reauthorize hands a refreshed token to the client acting as its user, returning
+// that client, or nil if none has been created.
+func (b *Bot) reauthorize(token Token) *helix.Client {
+	b.users.mu.Lock()
+	defer b.users.mu.Unlock()
+
+	client := b.users.clients[token.UserID]
+	if token.UserID == b.transport.UserID() {
+		client = b.helix
+	}
+	if client != nil {
+		client.SetUserAccessToken(token.AccessToken)
+		client.SetRefreshToken(token.RefreshToken)
+	}
+	return client
+}
+
+// forget stops acting as a user, removing their token from the client acting as them.
+func (b *Bot) forget(userID string) {
+	b.users.mu.Lock()
+	defer b.users.mu.Unlock()
+
+	delete(b.users.clients, userID)
+	if userID == b.transport.UserID() {
+		b.helix.SetUserAccessToken("")
+		b.helix.SetRefreshToken("")
+		b.transport.SetUserID("")
+	}
+}
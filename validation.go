@@ -0,0 +1,193 @@
+package twitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// tokenValidationInterval is how often stored user tokens are validated, as required by Twitch.
+const tokenValidationInterval = time.Hour
+
+// validateTokens validates every stored token, then again every tokenValidationInterval until ctx is done.
+func (b *Bot) validateTokens(ctx context.Context) {
+	ticker := time.NewTicker(tokenValidationInterval)
+	defer ticker.Stop()
+
+	for {
+		b.validateStoredTokens(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// validateStoredTokens validates every stored token once.
+func (b *Bot) validateStoredTokens(ctx context.Context) {
+	tokens, err := b.tokens.List(ctx)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("failed to list stored tokens")
+		return
+	}
+
+	for _, token := range tokens {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := b.checkToken(ctx, token); err != nil {
+			b.logger.Error().
+				Err(err).
+				Str("user_id", token.UserID).
+				Msg("failed to validate token")
+		}
+	}
+}
+
+// checkToken validates a stored token and records its scopes and expiry, refreshing it if
+// it has expired or will before the next validation.
+//
+// The token is loaded again before it is saved, so a refresh made meanwhile by a helix
+// client is not overwritten. A token that is rejected and cannot be refreshed is removed
+// and reported to OnTokenInvalid.
+func (b *Bot) checkToken(ctx context.Context, token Token) error {
+	info, err := b.validateToken(ctx, token.AccessToken)
+
+	// a rejected access token has expired or been revoked, which only a failed refresh tells apart
+	rejected := errors.Is(err, errTokenInvalid)
+	if err != nil && !rejected {
+		return fmt.Errorf("checkToken: %w", err)
+	}
+
+	current, changed, err := b.reloadToken(ctx, token)
+	if err != nil || changed {
+		return err
+	}
+
+	if !rejected {
+		current.Login = info.Login
+		current.Scopes = info.Scopes
+
+		// tokens reporting no expiry never need refreshing
+		if info.ExpiresIn == 0 {
+			current.ExpiresAt = time.Time{}
+		} else {
+			current.ExpiresAt = b.now().Add(time.Duration(info.ExpiresIn) * time.Second)
+		}
+
+		if info.ExpiresIn == 0 || time.Duration(info.ExpiresIn)*time.Second > tokenValidationInterval {
+			if err := b.tokens.Save(ctx, current); err != nil {
+				return fmt.Errorf("checkToken: %w", err)
+			}
+			return nil
+		}
+	}
+
+	refreshed, err := b.refreshToken(ctx, current)
+	if errors.Is(err, errTokenInvalid) {
+		if _, changed, err := b.reloadToken(ctx, current); err != nil || changed {
+			return err
+		}
+		return b.invalidate(ctx, current, "access token and refresh token were rejected")
+	}
+	if err != nil {
+		return fmt.Errorf("checkToken: %w", err)
+	}
+
+	if err := b.tokens.Save(ctx, refreshed); err != nil {
+		return fmt.Errorf("checkToken: %w", err)
+	}
+	if client := b.reauthorize(refreshed); client != nil {
+		b.engine.OnClientRefresh(ctx, client)
+	}
+
+	b.logger.Debug().
+		Str("user_id", token.UserID).
+		Msg("refreshed token")
+	return nil
+}
+
+// reloadToken loads the stored token of token's user, reporting whether it has been
+// refreshed or removed since token was read, in which case it needs no further checks.
+func (b *Bot) reloadToken(ctx context.Context, token Token) (Token, bool, error) {
+	current, err := b.tokens.Load(ctx, token.UserID)
+	if errors.Is(err, ErrTokenNotFound) {
+		return current, true, nil
+	}
+	if err != nil {
+		return current, false, fmt.Errorf("checkToken: %w", err)
+	}
+	return current, current.AccessToken != token.AccessToken, nil
+}
+
+// refreshToken exchanges the refresh token of token for new credentials, returning
+// errTokenInvalid if Twitch rejects it.
+func (b *Bot) refreshToken(ctx context.Context, token Token) (Token, error) {
+	data := url.Values{}
+	data.Set("client_id", b.config.ClientID)
+	data.Set("client_secret", b.secret("CLIENT_SECRET"))
+	data.Set("grant_type", "refresh_token")
+	data.Set("refresh_token", token.RefreshToken)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://id.twitch.tv/oauth2/token", strings.NewReader(data.Encode()))
+	if err != nil {
+		return token, fmt.Errorf("refreshToken: failed building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return token, fmt.Errorf("refreshToken: failed executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return token, errTokenInvalid
+	}
+	if resp.StatusCode != http.StatusOK {
+		return token, fmt.Errorf("refreshToken: unexpected status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		AccessToken  string   `json:"access_token"`
+		RefreshToken string   `json:"refresh_token"`
+		ExpiresIn    int      `json:"expires_in"`
+		Scopes       []string `json:"scope"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return token, fmt.Errorf("refreshToken: failed decoding response: %w", err)
+	}
+
+	token.AccessToken = body.AccessToken
+	token.RefreshToken = body.RefreshToken
+	token.ObtainedAt = b.now()
+	token.ExpiresAt = token.ObtainedAt.Add(time.Duration(body.ExpiresIn) * time.Second)
+	if len(body.Scopes) > 0 {
+		token.Scopes = body.Scopes
+	}
+	return token, nil
+}
+
+// invalidate removes a token that can no longer be used and reports it to OnTokenInvalid.
+func (b *Bot) invalidate(ctx context.Context, token Token, reason string) error {
+	if err := b.tokens.Delete(ctx, token.UserID); err != nil {
+		return fmt.Errorf("invalidate: %w", err)
+	}
+	b.forget(token.UserID)
+
+	b.logger.Warn().
+		Str("user_id", token.UserID).
+		Str("login", token.Login).
+		Str("reason", reason).
+		Msg("removed invalid token")
+
+	b.engine.OnTokenInvalid(ctx, token.UserID, reason)
+	return nil
+}
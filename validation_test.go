@@ -0,0 +1,123 @@
+package twitchgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+// validationBot returns a bot storing token, whose calls to the validate endpoint call
+// during before responding with status and body. Refresh requests are rejected and counted.
+func validationBot(t *testing.T, token Token, during func(b *Bot), status int, body string) (*Bot, *atomic.Int32) {
+	t.Helper()
+
+	var b *Bot
+	var refreshes atomic.Int32
+	b = newTestBot(t, nil, WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		switch r.URL.Path {
+		case "/oauth2/validate":
+			during(b)
+			return jsonResponse(r, status, body), nil
+		case "/oauth2/token":
+			refreshes.Add(1)
+			return jsonResponse(r, http.StatusBadRequest, `{"status":400,"message":"Invalid refresh token"}`), nil
+		}
+		t.Errorf("unexpected request to %s", r.URL)
+		return jsonResponse(r, http.StatusNotFound, ""), nil
+	})))
+
+	if err := b.tokens.Save(context.Background(), token); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	return b, &refreshes
+}
+
+func TestCheckTokenKeepsChangesMadeDuringValidation(t *testing.T) {
+	listed := Token{UserID: "1234", AccessToken: "access", RefreshToken: "refresh"}
+	b, _ := validationBot(t, listed, func(b *Bot) {
+		// a helix client saves the token while it is being validated
+		token, _ := b.tokens.Load(context.Background(), "1234")
+		token.Bot = true
+		_ = b.tokens.Save(context.Background(), token)
+	}, http.StatusOK, `{"client_id":"client","login":"bot","scopes":["user:read:chat"],"user_id":"1234","expires_in":14400}`)
+
+	if err := b.checkToken(context.Background(), listed); err != nil {
+		t.Fatalf("checkToken: %v", err)
+	}
+
+	stored, err := b.tokens.Load(context.Background(), "1234")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !stored.Bot {
+		t.Error("change saved during validation was overwritten")
+	}
+	if stored.Login != "bot" || len(stored.Scopes) != 1 || stored.ExpiresAt.IsZero() {
+		t.Errorf("stored token = %+v, want the validated login, scopes and expiry", stored)
+	}
+}
+
+func TestCheckTokenSkipsTokensRefreshedDuringValidation(t *testing.T) {
+	listed := Token{UserID: "1234", AccessToken: "expired", RefreshToken: "refresh"}
+	refreshed := Token{UserID: "1234", AccessToken: "fresh", RefreshToken: "refresh-2"}
+
+	b, refreshes := validationBot(t, listed, func(b *Bot) {
+		_ = b.tokens.Save(context.Background(), refreshed)
+	}, http.StatusUnauthorized, `{"status":401,"message":"invalid access token"}`)
+
+	invalid := make(chan string, 1)
+	b.engine.add(NewPlugin(&tokenInvalidEngine{invalid: invalid}, "Alerts", 0, true, false))
+
+	if err := b.checkToken(context.Background(), listed); err != nil {
+		t.Fatalf("checkToken: %v", err)
+	}
+
+	stored, err := b.tokens.Load(context.Background(), "1234")
+	if err != nil {
+		t.Fatalf("Load: %v, want the refreshed token kept", err)
+	}
+	if stored.AccessToken != "fresh" || stored.RefreshToken != "refresh-2" {
+		t.Errorf("stored token = %+v, want the token refreshed meanwhile", stored)
+	}
+	if n := refreshes.Load(); n != 0 {
+		t.Errorf("refresh requests = %d, want 0", n)
+	}
+	select {
+	case userID := <-invalid:
+		t.Errorf("OnTokenInvalid called for %s", userID)
+	default:
+	}
+}
+
+func TestCheckTokenInvalidatesRejectedTokens(t *testing.T) {
+	listed := Token{UserID: "1234", AccessToken: "revoked", RefreshToken: "revoked"}
+	b, refreshes := validationBot(t, listed, func(b *Bot) {}, http.StatusUnauthorized, `{"status":401,"message":"invalid access token"}`)
+
+	invalid := make(chan string, 1)
+	b.engine.add(NewPlugin(&tokenInvalidEngine{invalid: invalid}, "Alerts", 0, true, false))
+
+	if err := b.checkToken(context.Background(), listed); err != nil {
+		t.Fatalf("checkToken: %v", err)
+	}
+
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("refresh requests = %d, want 1", n)
+	}
+	if userID := receive(t, invalid, "OnTokenInvalid"); userID != "1234" {
+		t.Errorf("OnTokenInvalid user = %q, want 1234", userID)
+	}
+	if _, err := b.tokens.Load(context.Background(), "1234"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Load: %v, want the token removed", err)
+	}
+}
+
+type tokenInvalidEngine struct {
+	BaseEngine
+	invalid chan string
+}
+
+func (e *tokenInvalidEngine) OnTokenInvalid(ctx context.Context, userID, reason string) {
+	e.invalid <- userID
+}
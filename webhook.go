@@ -6,14 +6,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
-	"strings"
 	"time"
-
-	"github.com/nicklaw5/helix/v2"
 )
 
 func (b *Bot) Handle(w http.ResponseWriter, r *http.Request) {
+	received := b.now()
+
 	b.logger.Debug().
 		Str("method", r.Method).
 		Str("path", r.URL.Path).
@@ -43,7 +41,7 @@ func (b *Bot) Handle(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid timestamp", http.StatusBadRequest)
 		return
 	}
-	if time.Since(t) > 10*time.Minute {
+	if b.now().Sub(t) > 10*time.Minute {
 		b.logger.Warn().Int("timestamp", int(t.Unix())).Msg("timestamp expired")
 		http.Error(w, "expired timestamp", http.StatusBadRequest)
 		return
@@ -59,7 +57,7 @@ func (b *Bot) Handle(w http.ResponseWriter, r *http.Request) {
 	b.logger.Debug().Int("body_bytes", len(body)).Msg("read request body")
 
 	msg := BuildHMACMessage(msgID, msgTS, body)
-	computed := ComputeHMAC([]byte(os.Getenv("CLIENT_SECRET")), msg)
+	computed := ComputeHMAC([]byte(b.secret("CLIENT_SECRET")), msg)
 
 	if !VerifyHMAC(computed, msgSig) {
 		b.logger.Warn().Msg("signature verification failed")
@@ -81,7 +79,14 @@ func (b *Bot) Handle(w http.ResponseWriter, r *http.Request) {
 	switch msgType {
 	case "notification":
 		b.logger.Debug().Msg("handling notification")
-		if err := processNotification(r.Context(), body, b); err != nil {
+		meta := Metadata{
+			MessageID:   msgID,
+			MessageType: msgType,
+			Timestamp:   t,
+			ReceivedAt:  received,
+			Transport:   Webhook,
+		}
+		if err := processNotification(r.Context(), meta, body, b); err != nil {
 			b.logger.Error().Err(err).Msg("failed to process notification")
 			http.Error(w, "failed to process notification", http.StatusBadRequest)
 			return
@@ -100,7 +105,19 @@ func (b *Bot) Handle(w http.ResponseWriter, r *http.Request) {
 		_, _ = w.Write(resp)
 
 	case "revocation":
-		b.logger.Warn().Msg("received subscription revocation")
+		b.logger.Debug().Msg("handling revocation")
+		meta := Metadata{
+			MessageID:   msgID,
+			MessageType: msgType,
+			Timestamp:   t,
+			ReceivedAt:  received,
+			Transport:   Webhook,
+		}
+		if err := processRevocation(r.Context(), meta, body, b); err != nil {
+			b.logger.Error().Err(err).Msg("failed to process revocation")
+			http.Error(w, "failed to process revocation", http.StatusBadRequest)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 
 	default:
@@ -110,13 +127,18 @@ func (b *Bot) Handle(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// processNotification processes a given notification
-func processNotification(ctx context.Context, body []byte, b *Bot) error {
+// processNotification decodes a notification and dispatches it to the handler
+// registered for its subscription type, or to the fallback handler if there is none.
+//
+// Handlers run behind the event middleware chain, with a context derived from the bot
+// rather than the delivering request. ctx, that of the delivering request or connection,
+// only bounds the wait for room in a full queue.
+func processNotification(ctx context.Context, meta Metadata, body []byte, b *Bot) error {
 	b.logger.Debug().Msg("processing notification wrapper")
 
 	var wrapper struct {
-		Subscription Subscription[any] `json:"subscription"`
-		Event        json.RawMessage   `json:"event"`
+		Subscription Subscription[json.RawMessage] `json:"subscription"`
+		Event        json.RawMessage               `json:"event"`
 	}
 
 	if err := json.Unmarshal(body, &wrapper); err != nil {
@@ -124,28 +146,43 @@ func processNotification(ctx context.Context, body []byte, b *Bot) error {
 		return err
 	}
 
-	subKey := strings.ToLower(wrapper.Subscription.Type + ".v" + wrapper.Subscription.Version)
-	b.logger.Debug().Str("subscription", subKey).Msg("parsed subscription type")
+	subKey := subscriptionKey(wrapper.Subscription.Type, wrapper.Subscription.Version)
+	meta.SubscriptionType = subKey
+	key := b.dispatcher.key(wrapper.Subscription, wrapper.Event)
+	b.logger.Debug().Str("subscription", string(subKey)).Msg("parsed subscription type")
 
-	switch subKey {
-	case string(ChannelChatMessage):
-		var event Response[helix.EventSubChannelChatMessageEvent, helix.EventSubCondition]
-		if err := json.Unmarshal(body, &event); err != nil {
-			b.logger.Error().Err(err).Msg("failed to unmarshal chat message event")
-			return err
-		}
+	event := &Event{
+		Metadata:     meta,
+		Subscription: wrapper.Subscription,
+		Payload:      wrapper.Event,
+	}
 
-		b.logger.Debug().
-			Str("broadcaster_id", event.Event.BroadcasterUserID).
-			Str("user_id", event.Event.ChatterUserID).
-			Msg("dispatching channel chat message handler")
+	var handler EventHandler
+	if reg, ok := b.registry.get(subKey); ok {
+		data, err := reg.decode(body)
+		if err != nil {
+			// the notification is still acknowledged, as a retry would be dropped as a duplicate
+			eventCtx, cancel := b.eventContext(meta)
+			defer cancel()
+			b.dispatcher.fail(eventCtx, meta, fmt.Errorf("processNotification: failed decoding event: %w", err))
+			return nil
+		}
 
-		go b.engine.OnChannelChatMessage(ctx, b.helix, event)
+		event.Data = data
+		handler = reg.handle
+		b.logger.Debug().Str("subscription", string(subKey)).Msg("dispatching handler")
+	} else if fallback := b.registry.getFallback(); fallback != nil {
+		handler = func(ctx context.Context, event *Event) error {
+			return fallback(ctx, b.helix, event.Subscription, event.Payload)
+		}
+		b.logger.Debug().Str("subscription", string(subKey)).Msg("dispatching fallback handler")
+	} else {
+		b.logger.Warn().Str("subscription", string(subKey)).Msg("no handler registered for subscription type")
 		return nil
-
-	default:
-		err := fmt.Errorf("unsupported subscription type: %s", wrapper.Subscription.Type)
-		b.logger.Warn().Err(err).Msg("unsupported subscription received")
-		return err
 	}
+
+	b.dispatch(ctx, key, meta, func(ctx context.Context) error {
+		return b.registry.chain(subKey, handler)(ctx, event)
+	})
+	return nil
 }
@@ -0,0 +1,81 @@
+package twitchgo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nicklaw5/helix/v2"
+)
+
+// postWebhook delivers a signed EventSub message to the bot's webhook handler.
+func postWebhook(t *testing.T, b *Bot, id, messageType, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	signature := ComputeHMAC([]byte(b.secret("CLIENT_SECRET")), BuildHMACMessage(id, timestamp, []byte(body)))
+
+	r := httptest.NewRequest(http.MethodPost, "/webhook/callback", strings.NewReader(body))
+	r.Header.Set("Twitch-Eventsub-Message-Id", id)
+	r.Header.Set("Twitch-Eventsub-Message-Type", messageType)
+	r.Header.Set("Twitch-Eventsub-Message-Timestamp", timestamp)
+	r.Header.Set("Twitch-Eventsub-Message-Signature", "sha256="+signature)
+
+	w := httptest.NewRecorder()
+	b.Handle(w, r)
+	return w
+}
+
+type handlerErrorEngine struct {
+	BaseEngine
+	errs chan error
+}
+
+func (e *handlerErrorEngine) OnHandlerError(ctx context.Context, event Metadata, err error) {
+	e.errs <- err
+}
+
+func TestWebhookNotification(t *testing.T) {
+	b := newTestBot(t, nil)
+
+	events := make(chan helix.EventSubStreamOnlineEvent, 1)
+	RegisterHandler(b, "stream.online", "1", func(ctx context.Context, api *helix.Client, r Response[helix.EventSubStreamOnlineEvent, helix.EventSubCondition]) error {
+		events <- r.Event
+		return nil
+	})
+
+	body := `{"subscription":{"id":"sub-1","type":"stream.online","version":"1","condition":{"broadcaster_user_id":"1234"}},` +
+		`"event":{"broadcaster_user_id":"1234","broadcaster_user_login":"streamer"}}`
+	if w := postWebhook(t, b, "message-1", "notification", body); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if event := receive(t, events, "stream.online notification"); event.BroadcasterUserID != "1234" {
+		t.Errorf("broadcaster = %q, want 1234", event.BroadcasterUserID)
+	}
+}
+
+func TestWebhookUndecodableNotificationIsAcknowledged(t *testing.T) {
+	engine := &handlerErrorEngine{errs: make(chan error, 1)}
+	b := newTestBot(t, engine)
+
+	RegisterHandler(b, "stream.online", "1", func(ctx context.Context, api *helix.Client, r Response[helix.EventSubStreamOnlineEvent, helix.EventSubCondition]) error {
+		t.Error("handler called for an undecodable event")
+		return nil
+	})
+
+	body := `{"subscription":{"id":"sub-1","type":"stream.online","version":"1"},"event":{"broadcaster_user_id":1234}}`
+	if w := postWebhook(t, b, "message-1", "notification", body); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if err := receive(t, engine.errs, "OnHandlerError"); !strings.Contains(err.Error(), "failed decoding event") {
+		t.Errorf("OnHandlerError got %v, want a decoding error", err)
+	}
+	if stats := b.DispatcherStats(); stats.Failed != 1 {
+		t.Errorf("Failed = %d, want 1", stats.Failed)
+	}
+}
@@ -0,0 +1,335 @@
+package twitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultWebsocketURL is the Twitch EventSub WebSocket endpoint.
+const DefaultWebsocketURL = "wss://eventsub.wss.twitch.tv/ws"
+
+const (
+	websocketWelcomeTimeout = 10 * time.Second
+	websocketKeepaliveGrace = 5 * time.Second
+	websocketReconnectGrace = 5 * time.Second
+	websocketMaxBackoff     = 2 * time.Minute
+)
+
+// WebsocketSession describes an EventSub WebSocket session as reported by Twitch.
+//
+// See: https://dev.twitch.tv/docs/eventsub/websocket-reference for more information.
+type WebsocketSession struct {
+	ID                      string    `json:"id"`
+	Status                  string    `json:"status"`
+	ConnectedAt             time.Time `json:"connected_at"`
+	KeepaliveTimeoutSeconds int       `json:"keepalive_timeout_seconds"`
+	ReconnectURL            string    `json:"reconnect_url"`
+}
+
+type websocketMessage struct {
+	Metadata websocketMetadata `json:"metadata"`
+	Payload  json.RawMessage   `json:"payload"`
+}
+
+type websocketMetadata struct {
+	MessageID           string    `json:"message_id"`
+	MessageType         string    `json:"message_type"`
+	MessageTimestamp    time.Time `json:"message_timestamp"`
+	SubscriptionType    string    `json:"subscription_type"`
+	SubscriptionVersion string    `json:"subscription_version"`
+}
+
+type websocketSessionPayload struct {
+	Session WebsocketSession `json:"session"`
+}
+
+// WebsocketClient receives EventSub notifications over a WebSocket connection
+// and feeds them into the same dispatch path as the webhook handler.
+type WebsocketClient struct {
+	bot       *Bot
+	url       string
+	dialer    *websocket.Dialer
+	mu        sync.RWMutex
+	session   WebsocketSession
+	onSession []func(ctx context.Context, session WebsocketSession)
+}
+
+// NewWebsocketClient creates a WebsocketClient that dispatches notifications to the bot.
+//
+// If url is empty, DefaultWebsocketURL is used.
+//
+// Example:
+//
+//	ws := twitchgo.NewWebsocketClient(bot, "ws://127.0.0.1:8080/ws")
+func NewWebsocketClient(b *Bot, url string) *WebsocketClient {
+	if url == "" {
+		url = DefaultWebsocketURL
+	}
+	return &WebsocketClient{
+		bot:    b,
+		url:    url,
+		dialer: websocket.DefaultDialer,
+	}
+}
+
+// Session returns the currently active WebSocket session.
+func (c *WebsocketClient) Session() WebsocketSession {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.session
+}
+
+// OnSession registers a function called whenever a session_welcome message is received.
+//
+// Subscriptions must be created with the new session ID within 10 seconds of the welcome,
+// so this is the place to create them.
+//
+// Example:
+//
+//	ws.OnSession(func(ctx context.Context, s twitchgo.WebsocketSession) {
+//	    // create subscriptions using s.ID
+//	})
+func (c *WebsocketClient) OnSession(fn func(ctx context.Context, session WebsocketSession)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onSession = append(c.onSession, fn)
+}
+
+// Run connects to the EventSub WebSocket and processes messages until ctx is cancelled.
+//
+// Lost connections and keepalive timeouts are retried with exponential backoff.
+// Reconnect messages are honoured by moving to the provided URL without losing subscriptions.
+func (c *WebsocketClient) Run(ctx context.Context) error {
+	backoff := time.Second
+	for {
+		conn, err := c.connect(ctx, c.url)
+		if err == nil {
+			backoff = time.Second
+			for err == nil {
+				conn, err = c.serve(ctx, conn)
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		c.bot.logger.Warn().Err(err).Dur("backoff", backoff).Msg("websocket session ended; reconnecting")
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > websocketMaxBackoff {
+			backoff = websocketMaxBackoff
+		}
+	}
+}
+
+// connect dials url and waits for the session_welcome message.
+func (c *WebsocketClient) connect(ctx context.Context, url string) (*websocket.Conn, error) {
+	c.bot.logger.Debug().Str("url", url).Msg("dialing EventSub websocket")
+
+	conn, _, err := c.dialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connect: failed dialing websocket: %w", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(websocketWelcomeTimeout))
+
+	var msg websocketMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("connect: failed reading welcome: %w", err)
+	}
+
+	if msg.Metadata.MessageType != "session_welcome" {
+		conn.Close()
+		return nil, fmt.Errorf("connect: expected session_welcome, got %s", msg.Metadata.MessageType)
+	}
+
+	var payload websocketSessionPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("connect: failed unmarshalling welcome: %w", err)
+	}
+
+	c.mu.Lock()
+	c.session = payload.Session
+	hooks := append([]func(context.Context, WebsocketSession){}, c.onSession...)
+	c.mu.Unlock()
+
+	c.bot.logger.Info().
+		Str("session_id", payload.Session.ID).
+		Int("keepalive_timeout_seconds", payload.Session.KeepaliveTimeoutSeconds).
+		Msg("EventSub websocket session established")
+
+	for _, fn := range hooks {
+		fn(ctx, payload.Session)
+	}
+
+	return conn, nil
+}
+
+// serve reads messages from conn until it fails or Twitch asks us to reconnect,
+// in which case the connection to the new URL is returned.
+func (c *WebsocketClient) serve(ctx context.Context, conn *websocket.Conn) (*websocket.Conn, error) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		keepalive := time.Duration(c.Session().KeepaliveTimeoutSeconds) * time.Second
+		if keepalive > 0 {
+			_ = conn.SetReadDeadline(time.Now().Add(keepalive + websocketKeepaliveGrace))
+		} else {
+			_ = conn.SetReadDeadline(time.Time{})
+		}
+
+		var msg websocketMessage
+		err := conn.ReadJSON(&msg)
+		received := c.bot.now()
+		if err != nil {
+			conn.Close()
+
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return nil, fmt.Errorf("serve: keepalive timeout after %s", keepalive)
+			}
+
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) {
+				return nil, fmt.Errorf("serve: websocket closed with code %d: %s", closeErr.Code, closeErr.Text)
+			}
+
+			return nil, fmt.Errorf("serve: failed reading message: %w", err)
+		}
+
+		if msg.Metadata.MessageType != "session_reconnect" {
+			c.handle(ctx, msg, received)
+			continue
+		}
+
+		var payload websocketSessionPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("serve: failed unmarshalling reconnect: %w", err)
+		}
+
+		c.bot.logger.Info().Str("url", payload.Session.ReconnectURL).Msg("EventSub websocket reconnect requested")
+		return c.reconnect(ctx, conn, payload.Session.ReconnectURL)
+	}
+}
+
+// reconnect connects to url while still handling messages sent on old, which Twitch keeps
+// delivering until the new connection is welcomed. Once it is, old is drained and closed.
+func (c *WebsocketClient) reconnect(ctx context.Context, old *websocket.Conn, url string) (*websocket.Conn, error) {
+	type result struct {
+		conn *websocket.Conn
+		err  error
+	}
+	connected := make(chan result, 1)
+	go func() {
+		conn, err := c.connect(ctx, url)
+		connected <- result{conn, err}
+	}()
+
+	type received struct {
+		msg websocketMessage
+		at  time.Time
+	}
+	messages := make(chan received)
+	go func() {
+		defer close(messages)
+		for {
+			var msg websocketMessage
+			if err := old.ReadJSON(&msg); err != nil {
+				return
+			}
+			messages <- received{msg, c.bot.now()}
+		}
+	}()
+
+	var next *result
+	for next == nil || messages != nil {
+		select {
+		case r, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+			c.handle(ctx, r.msg, r.at)
+
+		case r := <-connected:
+			next = &r
+			connected = nil
+
+			// Twitch closes the old connection once the new one is welcomed, but do not wait on it forever
+			_ = old.SetReadDeadline(time.Now().Add(websocketReconnectGrace))
+		}
+	}
+
+	old.Close()
+	return next.conn, next.err
+}
+
+// handle processes a message received at the given time, other than session_reconnect.
+func (c *WebsocketClient) handle(ctx context.Context, msg websocketMessage, received time.Time) {
+	b := c.bot
+	meta := Metadata{
+		MessageID:   msg.Metadata.MessageID,
+		MessageType: msg.Metadata.MessageType,
+		Timestamp:   msg.Metadata.MessageTimestamp,
+		ReceivedAt:  received,
+		Transport:   Websocket,
+	}
+
+	b.logger.Debug().
+		Str("message_id", msg.Metadata.MessageID).
+		Str("message_type", msg.Metadata.MessageType).
+		Msg("received EventSub websocket message")
+
+	switch msg.Metadata.MessageType {
+	case "session_keepalive":
+		// receiving it is enough to keep the session alive
+
+	case "notification":
+		if b.cache.Exists(msg.Metadata.MessageID) {
+			b.logger.Debug().Str("message_id", msg.Metadata.MessageID).Msg("duplicate message; already processed")
+			return
+		}
+		b.cache.Add(msg.Metadata.MessageID)
+
+		if err := processNotification(ctx, meta, msg.Payload, b); err != nil {
+			b.logger.Error().Err(err).Msg("failed to process notification")
+		}
+
+	case "revocation":
+		if err := processRevocation(ctx, meta, msg.Payload, b); err != nil {
+			b.logger.Error().Err(err).Msg("failed to process revocation")
+		}
+
+	case "session_reconnect":
+		b.logger.Debug().Msg("ignoring reconnect request while reconnecting")
+
+	default:
+		b.logger.Warn().Str("message_type", msg.Metadata.MessageType).Msg("unknown message type")
+	}
+}
@@ -0,0 +1,320 @@
+package twitchgo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/nicklaw5/helix/v2"
+)
+
+// wsServer stands in for the Twitch EventSub WebSocket endpoint, handing each
+// connection and its index, starting at 1, to a script.
+type wsServer struct {
+	*httptest.Server
+	conns atomic.Int32
+}
+
+func newWSServer(t *testing.T, script func(conn *websocket.Conn, n int)) *wsServer {
+	t.Helper()
+
+	s := &wsServer{}
+	upgrader := websocket.Upgrader{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		script(conn, int(s.conns.Add(1)))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *wsServer) URL() string {
+	return "ws" + strings.TrimPrefix(s.Server.URL, "http")
+}
+
+// wsSend writes an EventSub message to conn.
+func wsSend(t *testing.T, conn *websocket.Conn, id, messageType string, payload interface{}) {
+	t.Helper()
+
+	err := conn.WriteJSON(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"message_id":        id,
+			"message_type":      messageType,
+			"message_timestamp": time.Now().UTC(),
+		},
+		"payload": payload,
+	})
+	if err != nil {
+		t.Errorf("write %s: %v", messageType, err)
+	}
+}
+
+// wsWelcome sends a session_welcome for session id to conn.
+func wsWelcome(t *testing.T, conn *websocket.Conn, id string, keepalive int) {
+	t.Helper()
+
+	wsSend(t, conn, "welcome-"+id, "session_welcome", map[string]interface{}{
+		"session": map[string]interface{}{
+			"id":                        id,
+			"status":                    "connected",
+			"keepalive_timeout_seconds": keepalive,
+		},
+	})
+}
+
+// wsHold keeps conn open until the client closes it.
+func wsHold(conn *websocket.Conn) {
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// runWebsocket runs the bot's websocket client against url until the test ends.
+func runWebsocket(t *testing.T, b *Bot, url string) *WebsocketClient {
+	t.Helper()
+
+	client := NewWebsocketClient(b, url)
+	b.websocket = client
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- client.Run(ctx) }()
+
+	t.Cleanup(func() {
+		cancel()
+		if err := receive(t, done, "Run to return"); err != nil {
+			t.Errorf("Run: %v", err)
+		}
+	})
+	return client
+}
+
+var streamOnlineSubscription = map[string]interface{}{
+	"id":        "sub-1",
+	"type":      "stream.online",
+	"version":   "1",
+	"status":    "enabled",
+	"condition": map[string]string{"broadcaster_user_id": "1234"},
+	"transport": map[string]string{"method": "websocket", "session_id": "session-1"},
+}
+
+func TestWebsocketWelcome(t *testing.T) {
+	b := newTestBot(t, nil)
+	server := newWSServer(t, func(conn *websocket.Conn, n int) {
+		wsWelcome(t, conn, "session-1", 10)
+		wsHold(conn)
+	})
+
+	sessions := make(chan WebsocketSession, 1)
+	client := NewWebsocketClient(b, server.URL())
+	client.OnSession(func(ctx context.Context, session WebsocketSession) {
+		sessions <- session
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- client.Run(ctx) }()
+
+	session := receive(t, sessions, "session_welcome")
+	if session.ID != "session-1" || session.KeepaliveTimeoutSeconds != 10 {
+		t.Errorf("session = %+v, want id session-1 with a 10s keepalive", session)
+	}
+	if got := client.Session().ID; got != "session-1" {
+		t.Errorf("Session().ID = %q, want session-1", got)
+	}
+
+	cancel()
+	if err := receive(t, done, "Run to return"); err != nil {
+		t.Errorf("Run: %v", err)
+	}
+}
+
+func TestWebsocketNotification(t *testing.T) {
+	b := newTestBot(t, nil)
+
+	events := make(chan helix.EventSubStreamOnlineEvent, 1)
+	RegisterHandler(b, "stream.online", "1", func(ctx context.Context, api *helix.Client, r Response[helix.EventSubStreamOnlineEvent, helix.EventSubCondition]) error {
+		if meta, ok := MetadataFromContext(ctx); !ok || meta.Transport != Websocket {
+			t.Errorf("metadata = %+v, want websocket transport", meta)
+		}
+		events <- r.Event
+		return nil
+	})
+
+	server := newWSServer(t, func(conn *websocket.Conn, n int) {
+		wsWelcome(t, conn, "session-1", 10)
+		wsSend(t, conn, "message-1", "notification", map[string]interface{}{
+			"subscription": streamOnlineSubscription,
+			"event": map[string]string{
+				"broadcaster_user_id":    "1234",
+				"broadcaster_user_login": "streamer",
+				"type":                   "live",
+			},
+		})
+		wsHold(conn)
+	})
+	runWebsocket(t, b, server.URL())
+
+	event := receive(t, events, "stream.online notification")
+	if event.BroadcasterUserID != "1234" || event.BroadcasterUserLogin != "streamer" {
+		t.Errorf("event = %+v, want broadcaster 1234 (streamer)", event)
+	}
+}
+
+func TestWebsocketReconnect(t *testing.T) {
+	b := newTestBot(t, nil)
+
+	events := make(chan string, 1)
+	RegisterHandler(b, "stream.online", "1", func(ctx context.Context, api *helix.Client, r Response[helix.EventSubStreamOnlineEvent, helix.EventSubCondition]) error {
+		events <- r.Event.BroadcasterUserLogin
+		return nil
+	})
+
+	sent := make(chan struct{})
+	welcomed := make(chan struct{})
+	closed := make(chan struct{})
+	next := newWSServer(t, func(conn *websocket.Conn, n int) {
+		// Twitch may keep delivering on the old connection until the new one is welcomed
+		<-sent
+		wsWelcome(t, conn, "session-2", 10)
+		close(welcomed)
+		wsHold(conn)
+	})
+	first := newWSServer(t, func(conn *websocket.Conn, n int) {
+		defer close(closed)
+
+		wsWelcome(t, conn, "session-1", 10)
+		wsSend(t, conn, "reconnect-1", "session_reconnect", map[string]interface{}{
+			"session": map[string]interface{}{
+				"id":            "session-1",
+				"status":        "reconnecting",
+				"reconnect_url": next.URL(),
+			},
+		})
+		wsSend(t, conn, "message-1", "notification", map[string]interface{}{
+			"subscription": streamOnlineSubscription,
+			"event": map[string]string{
+				"broadcaster_user_id":    "1234",
+				"broadcaster_user_login": "streamer",
+				"type":                   "live",
+			},
+		})
+		close(sent)
+
+		<-welcomed
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4004, "reconnect grace time expired"))
+		wsHold(conn)
+	})
+
+	sessions := make(chan string, 2)
+	client := NewWebsocketClient(b, first.URL())
+	client.OnSession(func(ctx context.Context, session WebsocketSession) {
+		sessions <- session.ID
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- client.Run(ctx) }()
+	defer func() {
+		cancel()
+		receive(t, done, "Run to return")
+	}()
+
+	if id := receive(t, sessions, "first welcome"); id != "session-1" {
+		t.Fatalf("first session = %q, want session-1", id)
+	}
+	if login := receive(t, events, "notification sent on the old connection"); login != "streamer" {
+		t.Errorf("notification for %q, want streamer", login)
+	}
+	if id := receive(t, sessions, "welcome after reconnect"); id != "session-2" {
+		t.Fatalf("session after reconnect = %q, want session-2", id)
+	}
+	receive(t, closed, "old connection to close")
+
+	if got := first.conns.Load(); got != 1 {
+		t.Errorf("connections to the old URL = %d, want 1", got)
+	}
+	if got := client.Session().ID; got != "session-2" {
+		t.Errorf("Session().ID = %q, want session-2", got)
+	}
+}
+
+func TestWebsocketKeepaliveTimeout(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for a keepalive timeout")
+	}
+
+	b := newTestBot(t, nil)
+
+	connected := make(chan time.Time, 2)
+	server := newWSServer(t, func(conn *websocket.Conn, n int) {
+		connected <- time.Now()
+		wsWelcome(t, conn, "session-"+strconv.Itoa(n), 1)
+		// never send a keepalive
+		wsHold(conn)
+	})
+	runWebsocket(t, b, server.URL())
+
+	first := receive(t, connected, "first connection")
+
+	var second time.Time
+	select {
+	case second = <-connected:
+	case <-time.After(websocketKeepaliveGrace + 5*time.Second):
+		t.Fatal("timed out waiting for the client to reconnect")
+	}
+
+	// the client waits out the keepalive and its grace period, then backs off for a second
+	if elapsed, min := second.Sub(first), time.Second+websocketKeepaliveGrace+time.Second; elapsed < min {
+		t.Errorf("reconnected after %s, want at least %s", elapsed, min)
+	}
+}
+
+type revocationEngine struct {
+	BaseEngine
+	revoked chan Subscription[helix.EventSubCondition]
+}
+
+func (e *revocationEngine) OnSubscriptionRevoked(ctx context.Context, api *helix.Client, subscription Subscription[helix.EventSubCondition]) error {
+	e.revoked <- subscription
+	return nil
+}
+
+func TestWebsocketRevocation(t *testing.T) {
+	engine := &revocationEngine{revoked: make(chan Subscription[helix.EventSubCondition], 1)}
+	b := newTestBot(t, engine)
+
+	revoked := map[string]interface{}{}
+	for k, v := range streamOnlineSubscription {
+		revoked[k] = v
+	}
+	revoked["status"] = "authorization_revoked"
+
+	server := newWSServer(t, func(conn *websocket.Conn, n int) {
+		wsWelcome(t, conn, "session-1", 10)
+		wsSend(t, conn, "revocation-1", "revocation", map[string]interface{}{
+			"subscription": revoked,
+		})
+		wsHold(conn)
+	})
+	runWebsocket(t, b, server.URL())
+
+	sub := receive(t, engine.revoked, "revocation")
+	if sub.ID != "sub-1" || sub.Status != AuthorizationRevoked || sub.Condition.BroadcasterUserID != "1234" {
+		t.Errorf("revoked subscription = %+v, want sub-1 revoked for authorization", sub)
+	}
+}